@@ -1,6 +1,8 @@
 module github.com/tencent/vectordatabase-sdk-go
 
-go 1.12
+// go 1.21: log/slog in tcvectordb/logger_slog.go and errors.Join in BulkWriter.Flush,
+// google.golang.org/grpc v1.65.0 requires go 1.21 as well
+go 1.21
 
 require (
 	github.com/pkg/errors v0.9.1
@@ -10,3 +12,14 @@ require (
 	google.golang.org/grpc v1.65.0
 	google.golang.org/protobuf v1.34.2
 )
+
+require (
+	github.com/clbanning/mxj v1.8.4 // indirect
+	github.com/google/go-querystring v1.0.0 // indirect
+	github.com/mitchellh/mapstructure v1.4.3 // indirect
+	github.com/mozillazg/go-httpheader v0.2.1 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
+)
@@ -20,8 +20,8 @@ package tcvectordb
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -63,10 +63,10 @@ type CreateCollectionResult struct {
 func (i *implementerCollection) ExistsCollection(ctx context.Context, name string) (bool, error) {
 	res, err := i.DescribeCollection(ctx, name)
 	if err != nil {
-		if strings.Contains(err.Error(), strconv.Itoa(ERR_UNDEFINED_COLLECTION)) {
+		if errors.Is(err, ErrCollectionNotFound) {
 			return false, nil
 		}
-		return false, fmt.Errorf("get collection %s failed, err: %v", name, err.Error())
+		return false, fmt.Errorf("get collection %s failed, err: %w", name, err)
 	}
 	if res == nil {
 		return false, fmt.Errorf("get collection %s failed", name)
@@ -78,10 +78,10 @@ func (i *implementerCollection) CreateCollectionIfNotExists(ctx context.Context,
 	indexes Indexes, params ...*CreateCollectionParams) (*Collection, error) {
 	res, err := i.DescribeCollection(ctx, name)
 	if err != nil {
-		if strings.Contains(err.Error(), strconv.Itoa(ERR_UNDEFINED_COLLECTION)) {
+		if errors.Is(err, ErrCollectionNotFound) {
 			return i.CreateCollection(ctx, name, shardNum, replicasNum, description, indexes, params...)
 		}
-		return nil, fmt.Errorf("get collection %s failed, err: %v", name, err.Error())
+		return nil, fmt.Errorf("get collection %s failed, err: %w", name, err)
 	}
 	if res == nil {
 		return nil, fmt.Errorf("get collection %s failed", name)
@@ -99,6 +99,7 @@ func (i *implementerCollection) CreateCollection(ctx context.Context, name strin
 	if i.database.IsAIDatabase() {
 		return nil, AIDbTypeError
 	}
+	forgetSchema(i.SdkClient, i.database.DatabaseName, name)
 	req := new(collection.CreateReq)
 	req.Database = i.database.DatabaseName
 	req.Collection = name
@@ -230,6 +231,7 @@ func (i *implementerCollection) DropCollection(ctx context.Context, name string)
 	if i.database.IsAIDatabase() {
 		return nil, AIDbTypeError
 	}
+	forgetSchema(i.SdkClient, i.database.DatabaseName, name)
 	req := new(collection.DropReq)
 	req.Database = i.database.DatabaseName
 	req.Collection = name
@@ -440,6 +442,25 @@ type Collection struct {
 	TtlConfig         *TtlConfig  `json:"ttlConfig,omitempty"`
 }
 
+// describe calls DescribeCollection of the database the collection belongs to
+func (c *Collection) describe(ctx context.Context) (*Collection, error) {
+	var db *Database
+	switch impl := c.DocumentInterface.(type) {
+	case *implementerDocument:
+		db = impl.database
+	case *rpcImplementerDocument:
+		db = impl.database
+	}
+	if db == nil {
+		return nil, fmt.Errorf("cannot describe the collection %s", c.CollectionName)
+	}
+	res, err := db.DescribeCollection(ctx, c.CollectionName)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Collection, nil
+}
+
 func (c *Collection) Debug(v bool) {
 	c.DocumentInterface.Debug(v)
 }
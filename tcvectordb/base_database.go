@@ -56,7 +56,7 @@ type CreateDatabaseResult struct {
 func (i *implementerDatabase) ExistsDatabase(ctx context.Context, name string) (bool, error) {
 	dbList, err := i.ListDatabase(ctx)
 	if err != nil {
-		return false, fmt.Errorf("judging whether the database exists failed. err is %v", err.Error())
+		return false, fmt.Errorf("judging whether the database exists failed. err is %w", err)
 	}
 	for _, db := range dbList.Databases {
 		if db.DatabaseName == name {
@@ -69,7 +69,7 @@ func (i *implementerDatabase) ExistsDatabase(ctx context.Context, name string) (
 func (i *implementerDatabase) CreateDatabaseIfNotExists(ctx context.Context, name string) (*CreateDatabaseResult, error) {
 	dbList, err := i.ListDatabase(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("judging whether the database exists failed. err is %v", err.Error())
+		return nil, fmt.Errorf("judging whether the database exists failed. err is %w", err)
 	}
 	for _, db := range dbList.Databases {
 		if db.DatabaseName == name {
@@ -148,15 +148,139 @@ type RerankOption struct {
 }
 type MatchOption struct {
 	FieldName string
-	Data      interface{}
-	Limit     *int
+	// Data: []encoder.SparseVecItem for one query, or [][]encoder.SparseVecItem for the batched queries
+	Data  interface{}
+	Limit *int
 }
 
 type AnnParam struct {
 	FieldName string
-	Data      interface{}
-	Params    *SearchDocParams
-	Limit     *int
+	// Data: []float32 for one query, or [][]float32 for the batched queries
+	Data   interface{}
+	Params *SearchDocParams
+	Limit  *int
+}
+
+// hybridRoutes the ann and match routes of HybridSearchDocumentParams with the default field names,
+// each route has the same number of queries
+type hybridRoutes struct {
+	ann   []hybridAnnRoute
+	match []hybridMatchRoute
+}
+
+type hybridAnnRoute struct {
+	*AnnParam
+	fieldName string
+	vectors   [][]float32
+}
+
+type hybridMatchRoute struct {
+	*MatchOption
+	fieldName string
+	vectors   [][]encoder.SparseVecItem
+}
+
+// newHybridRoutes checks the routes of the hybrid search, and the rerank weights against the fields of the routes
+func newHybridRoutes(params HybridSearchDocumentParams) (*hybridRoutes, error) {
+	routes := new(hybridRoutes)
+	queries := -1
+	checkQueries := func(n int, fieldName string) error {
+		if n == 0 {
+			return fmt.Errorf("hybridSearch failed, the data of the field %s is empty", fieldName)
+		}
+		if queries != -1 && n != queries {
+			return fmt.Errorf("hybridSearch failed, the field %s has %d queries, but the others have %d", fieldName, n, queries)
+		}
+		queries = n
+		return nil
+	}
+	fields := make(map[string]int)
+	for _, annParam := range params.AnnParams {
+		if annParam == nil {
+			continue
+		}
+		route := hybridAnnRoute{AnnParam: annParam, fieldName: "vector"}
+		if annParam.FieldName != "" {
+			route.fieldName = annParam.FieldName
+		}
+		switch data := annParam.Data.(type) {
+		case []float32:
+			route.vectors = [][]float32{data}
+		case [][]float32:
+			route.vectors = data
+		default:
+			return nil, fmt.Errorf("hybridSearch failed, because of AnnParam.Data field type, " +
+				"which must be []float32 or [][]float32")
+		}
+		if err := checkQueries(len(route.vectors), route.fieldName); err != nil {
+			return nil, err
+		}
+		fields[route.fieldName]++
+		routes.ann = append(routes.ann, route)
+	}
+	for _, matchParam := range params.Match {
+		if matchParam == nil {
+			continue
+		}
+		route := hybridMatchRoute{MatchOption: matchParam, fieldName: "sparse_vector"}
+		if matchParam.FieldName != "" {
+			route.fieldName = matchParam.FieldName
+		}
+		switch data := matchParam.Data.(type) {
+		case []encoder.SparseVecItem:
+			route.vectors = [][]encoder.SparseVecItem{data}
+		case [][]encoder.SparseVecItem:
+			route.vectors = data
+		default:
+			return nil, fmt.Errorf("hybridSearch failed, because of Match.Data field type, " +
+				"which must be []encoder.SparseVecItem or [][]encoder.SparseVecItem")
+		}
+		if err := checkQueries(len(route.vectors), route.fieldName); err != nil {
+			return nil, err
+		}
+		fields[route.fieldName]++
+		routes.match = append(routes.match, route)
+	}
+	if len(routes.ann)+len(routes.match) == 0 {
+		return nil, fmt.Errorf("hybridSearch failed, neither AnnParams nor Match is set")
+	}
+
+	rerank := params.Rerank
+	if rerank == nil {
+		// one ann and one match route are merged by the server as before, the several routes of
+		// the same kind need the Rerank
+		if len(routes.ann) > 1 || len(routes.match) > 1 {
+			return nil, fmt.Errorf("hybridSearch failed, the Rerank is required to merge %d ann and %d match routes",
+				len(routes.ann), len(routes.match))
+		}
+		return routes, nil
+	}
+	if len(rerank.FieldList) != len(rerank.Weight) {
+		return nil, fmt.Errorf("the length of fieldlist should be equal with the length of weights")
+	}
+	if rerank.Method != RerankWeighted {
+		return routes, nil
+	}
+	weighted := make(map[string]bool, len(rerank.FieldList))
+	for _, fieldName := range rerank.FieldList {
+		if fields[fieldName] == 0 {
+			return nil, fmt.Errorf("hybridSearch failed, the rerank field %s is not searched", fieldName)
+		}
+		if weighted[fieldName] {
+			return nil, fmt.Errorf("hybridSearch failed, the rerank field %s is weighted twice", fieldName)
+		}
+		weighted[fieldName] = true
+	}
+	for fieldName, n := range fields {
+		if !weighted[fieldName] {
+			return nil, fmt.Errorf("hybridSearch failed, the searched field %s has no rerank weight", fieldName)
+		}
+		if n > 1 {
+			return nil, fmt.Errorf("hybridSearch failed, the field %s is searched by %d routes, "+
+				"which could not be weighted separately", fieldName, n)
+		}
+	}
+	return routes, nil
 }
 
 func (i *implementerDocument) HybridSearch(ctx context.Context, params HybridSearchDocumentParams) (*SearchDocumentResult, error) {
@@ -307,6 +431,11 @@ func (i *implementerFlatDocument) Query(ctx context.Context, databaseName, colle
 		req.Query.Limit = param.Limit
 	}
 
+	if err := validateRequestSchema(ctx, i.SdkClient, databaseName, collectionName,
+		req.Query.Filter, req.Query.OutputFields); err != nil {
+		return nil, err
+	}
+
 	res := new(document.QueryRes)
 	err := i.Request(ctx, req, res)
 	if err != nil {
@@ -385,6 +514,11 @@ func (i *implementerFlatDocument) search(ctx context.Context, databaseName, coll
 		}
 	}
 
+	if err := validateRequestSchema(ctx, i.SdkClient, databaseName, collectionName,
+		req.Search.Filter, req.Search.OutputFields); err != nil {
+		return nil, err
+	}
+
 	res := new(document.SearchRes)
 	err := i.Request(ctx, req, res)
 	if err != nil {
@@ -423,58 +557,44 @@ func (i *implementerFlatDocument) HybridSearch(ctx context.Context, databaseName
 	req.Search.AnnParams = make([]*document.AnnParam, 0)
 	req.Search.Match = make([]*document.MatchOption, 0)
 
-	for i, annParam := range params.AnnParams {
-		fieldName := "vector"
-		if annParam.FieldName != "" {
-			fieldName = annParam.FieldName
+	routes, err := newHybridRoutes(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range routes.ann {
+		annParam := &document.AnnParam{
+			FieldName: route.fieldName,
+			Limit:     route.Limit,
+			Data:      make([]interface{}, 0, len(route.vectors)),
 		}
-		req.Search.AnnParams = append(req.Search.AnnParams, &document.AnnParam{
-			FieldName: fieldName,
-			Limit:     annParam.Limit,
-		})
-
-		req.Search.AnnParams[i].Data = make([]interface{}, 0)
-		if vec, ok := annParam.Data.([]float32); ok {
-			req.Search.AnnParams[i].Data = append(req.Search.AnnParams[i].Data, vec)
-		} else {
-			return nil, fmt.Errorf("hybridSearch failed, because of AnnParam.Data field type, " +
-				"which must be []float32")
+		for _, vec := range route.vectors {
+			annParam.Data = append(annParam.Data, vec)
 		}
-
-		if annParam.Params != nil {
-			req.Search.AnnParams[i].Params = new(document.SearchParams)
-			req.Search.AnnParams[i].Params.Nprobe = annParam.Params.Nprobe
-			req.Search.AnnParams[i].Params.Ef = annParam.Params.Ef
-			req.Search.AnnParams[i].Params.Radius = annParam.Params.Radius
+		if route.Params != nil {
+			annParam.Params = new(document.SearchParams)
+			annParam.Params.Nprobe = route.Params.Nprobe
+			annParam.Params.Ef = route.Params.Ef
+			annParam.Params.Radius = route.Params.Radius
 		}
-		break
+		req.Search.AnnParams = append(req.Search.AnnParams, annParam)
 	}
 
-	for i, matchParam := range params.Match {
-		fieldName := "sparse_vector"
-		if matchParam.FieldName != "" {
-			fieldName = matchParam.FieldName
+	for _, route := range routes.match {
+		matchParam := &document.MatchOption{
+			FieldName: route.fieldName,
+			Data:      make([][][]interface{}, 0, len(route.vectors)),
 		}
-		req.Search.Match = append(req.Search.Match, &document.MatchOption{
-			FieldName: fieldName,
-		})
-
-		req.Search.Match[i].Data = make([][][]interface{}, 0)
-		if svs, ok := matchParam.Data.([]encoder.SparseVecItem); ok {
-			sparseVector := make([][]interface{}, 0)
+		for _, svs := range route.vectors {
+			sparseVector := make([][]interface{}, 0, len(svs))
 			for _, svItem := range svs {
 				sparseVector = append(sparseVector, []interface{}{svItem.TermId, svItem.Score})
 			}
-			req.Search.Match[i].Data = append(req.Search.Match[i].Data, sparseVector)
-		} else {
-			return nil, fmt.Errorf("hybridSearch failed, because of Match.Data field type, " +
-				"which must be []encoder.SparseVecItem")
+			matchParam.Data = append(matchParam.Data, sparseVector)
 		}
-
-		if matchParam.Limit != nil {
-			req.Search.Match[i].Limit = *matchParam.Limit
+		if route.Limit != nil {
+			matchParam.Limit = *route.Limit
 		}
-		break
+		req.Search.Match = append(req.Search.Match, matchParam)
 	}
 
 	if params.Rerank != nil {
@@ -490,8 +610,13 @@ func (i *implementerFlatDocument) HybridSearch(ctx context.Context, databaseName
 	req.Search.OutputFields = params.OutputFields
 	req.Search.Limit = params.Limit
 
+	if err = validateRequestSchema(ctx, i.SdkClient, databaseName, collectionName,
+		req.Search.Filter, req.Search.OutputFields); err != nil {
+		return nil, err
+	}
+
 	res := new(document.SearchRes)
-	err := i.Request(ctx, req, res)
+	err = i.Request(ctx, req, res)
 	if err != nil {
 		return nil, err
 	}
@@ -538,6 +663,10 @@ func (i *implementerFlatDocument) Delete(ctx context.Context, databaseName, coll
 		Filter:      param.Filter.Cond(),
 	}
 
+	if err := validateRequestSchema(ctx, i.SdkClient, databaseName, collectionName, req.Query.Filter, nil); err != nil {
+		return nil, err
+	}
+
 	res := new(document.DeleteRes)
 	result := new(DeleteDocumentResult)
 	err := i.Request(ctx, req, res)
@@ -594,6 +723,10 @@ func (i *implementerFlatDocument) Update(ctx context.Context, databaseName, coll
 			"which must be map[string]Field or map[string]interface{}")
 	}
 
+	if err := validateRequestSchema(ctx, i.SdkClient, databaseName, collectionName, req.Query.Filter, nil); err != nil {
+		return nil, err
+	}
+
 	res := new(document.UpdateRes)
 	result := new(UpdateDocumentResult)
 	err := i.Request(ctx, req, res)
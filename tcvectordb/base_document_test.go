@@ -0,0 +1,58 @@
+package tcvectordb
+
+import (
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+)
+
+func TestNewHybridRoutes(t *testing.T) {
+	sparse := []encoder.SparseVecItem{{TermId: 1, Score: 1}}
+	valid := []HybridSearchDocumentParams{
+		{AnnParams: []*AnnParam{{Data: []float32{1, 0}}}},
+		// one ann and one match route need no rerank, as before the multiple routes
+		{AnnParams: []*AnnParam{{Data: []float32{1, 0}}}, Match: []*MatchOption{{Data: sparse}}},
+		{
+			AnnParams: []*AnnParam{{Data: [][]float32{{1, 0}, {0, 1}}}, {FieldName: "title_vector", Data: [][]float32{{1, 0}, {0, 1}}}},
+			Rerank:    &RerankOption{Method: RerankRrf},
+		},
+		{
+			AnnParams: []*AnnParam{{Data: []float32{1, 0}}, {FieldName: "title_vector", Data: []float32{1, 0}}},
+			Match:     []*MatchOption{{Data: sparse}},
+			Rerank: &RerankOption{Method: RerankWeighted, FieldList: []string{"vector", "title_vector", "sparse_vector"},
+				Weight: []float32{0.2, 0.7, 0.1}},
+		},
+	}
+	for _, params := range valid {
+		if _, err := newHybridRoutes(params); err != nil {
+			t.Fatalf("unexpected error of %+v: %v", params, err)
+		}
+	}
+
+	twoAnn := []*AnnParam{{Data: []float32{1, 0}}, {FieldName: "title_vector", Data: []float32{1, 0}}}
+	invalid := map[string]HybridSearchDocumentParams{
+		"no route":           {},
+		"wrong data type":    {AnnParams: []*AnnParam{{Data: []float64{1, 0}}}},
+		"empty data":         {AnnParams: []*AnnParam{{Data: [][]float32{}}}},
+		"rerank is required": {AnnParams: twoAnn},
+		"different queries": {
+			AnnParams: []*AnnParam{{Data: [][]float32{{1, 0}, {0, 1}}}, {FieldName: "title_vector", Data: []float32{1, 0}}},
+			Rerank:    &RerankOption{Method: RerankRrf},
+		},
+		"weights mismatch": {AnnParams: twoAnn,
+			Rerank: &RerankOption{Method: RerankWeighted, FieldList: []string{"vector"}, Weight: []float32{0.5, 0.5}}},
+		"field not searched": {AnnParams: twoAnn,
+			Rerank: &RerankOption{Method: RerankWeighted, FieldList: []string{"vector", "other"}, Weight: []float32{0.5, 0.5}}},
+		"field without weight": {AnnParams: twoAnn,
+			Rerank: &RerankOption{Method: RerankWeighted, FieldList: []string{"vector"}, Weight: []float32{1}}},
+		"field weighted twice": {AnnParams: twoAnn,
+			Rerank: &RerankOption{Method: RerankWeighted, FieldList: []string{"vector", "vector"}, Weight: []float32{0.5, 0.5}}},
+		"field searched twice": {AnnParams: []*AnnParam{{Data: []float32{1, 0}}, {Data: []float32{0, 1}}},
+			Rerank: &RerankOption{Method: RerankWeighted, FieldList: []string{"vector"}, Weight: []float32{1}}},
+	}
+	for name, params := range invalid {
+		if _, err := newHybridRoutes(params); err == nil {
+			t.Fatalf("%s: expect the params rejected", name)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// ErrCircuitOpen is returned without sending the request when the circuit breakers of the endpoints are open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerOption the circuit breaker of each endpoint. It opens after ConsecutiveFailures
+// failures in a row, or when the failure rate in Window exceeds FailureRate. While open, the requests
+// are rejected with ErrCircuitOpen. After OpenTimeout, HalfOpenRequests probe requests are let through,
+// the breaker closes if they succeed and opens again otherwise.
+type CircuitBreakerOption struct {
+	// ConsecutiveFailures: default 5
+	ConsecutiveFailures int
+	// FailureRate: 0~1, default 0 means only ConsecutiveFailures is used
+	FailureRate float64
+	// MinRequests: the min requests in Window before FailureRate is checked, default 20
+	MinRequests int
+	// Window: the time window of FailureRate, default 10s
+	Window time.Duration
+	// OpenTimeout: how long the breaker stays open before probing, default 30s
+	OpenTimeout time.Duration
+	// HalfOpenRequests: the concurrent probe requests in the half-open state, default 1
+	HalfOpenRequests int
+	// IsFailure: decides whether an error counts as a failure,
+	// default the transient errors of DefaultRetryable and the timeouts
+	IsFailure func(err error) bool
+	// OnStateChange: called when the breaker of an endpoint changes state
+	OnStateChange func(endpoint string, from, to CircuitState)
+}
+
+func circuitBreakerMerge(o CircuitBreakerOption) CircuitBreakerOption {
+	if o.ConsecutiveFailures == 0 {
+		o.ConsecutiveFailures = 5
+	}
+	if o.MinRequests == 0 {
+		o.MinRequests = 20
+	}
+	if o.Window == 0 {
+		o.Window = 10 * time.Second
+	}
+	if o.OpenTimeout == 0 {
+		o.OpenTimeout = 30 * time.Second
+	}
+	if o.HalfOpenRequests == 0 {
+		o.HalfOpenRequests = 1
+	}
+	if o.IsFailure == nil {
+		o.IsFailure = func(err error) bool {
+			return DefaultRetryable(err) || errors.Is(err, context.DeadlineExceeded)
+		}
+	}
+	return o
+}
+
+type circuitBreaker struct {
+	option   *CircuitBreakerOption
+	endpoint string
+
+	mu               sync.Mutex
+	state            CircuitState
+	consecutive      int
+	windowStart      time.Time
+	requests         int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+}
+
+func newCircuitBreaker(option *CircuitBreakerOption, endpoint string) *circuitBreaker {
+	if option == nil {
+		return nil
+	}
+	return &circuitBreaker{option: option, endpoint: endpoint, windowStart: time.Now()}
+}
+
+// open reports whether the breaker rejects the requests now, without changing the state
+func (b *circuitBreaker) open(now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		return now.Sub(b.openedAt) < b.option.OpenTimeout
+	case CircuitHalfOpen:
+		return b.halfOpenInFlight >= b.option.HalfOpenRequests
+	}
+	return false
+}
+
+// allow reports whether a request could be sent, and counts it as a probe in the half-open state
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	from := b.state
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.option.OpenTimeout {
+		b.state = CircuitHalfOpen
+		b.halfOpenInFlight = 0
+	}
+	allowed := true
+	switch b.state {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight >= b.option.HalfOpenRequests {
+			allowed = false
+		} else {
+			b.halfOpenInFlight++
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return allowed
+}
+
+// record the result of an allowed request
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+	failed := err != nil && b.option.IsFailure(err)
+	now := time.Now()
+
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case CircuitHalfOpen:
+		b.halfOpenInFlight--
+		if failed {
+			b.trip(now)
+		} else if err == nil {
+			b.reset(now)
+		}
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.option.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+		b.requests++
+		if failed {
+			b.failures++
+			b.consecutive++
+		} else {
+			b.consecutive = 0
+		}
+		if b.consecutive >= b.option.ConsecutiveFailures ||
+			(b.option.FailureRate > 0 && b.requests >= b.option.MinRequests &&
+				float64(b.failures)/float64(b.requests) >= b.option.FailureRate) {
+			b.trip(now)
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *circuitBreaker) trip(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.halfOpenInFlight = 0
+}
+
+func (b *circuitBreaker) reset(now time.Time) {
+	b.state = CircuitClosed
+	b.consecutive = 0
+	b.windowStart, b.requests, b.failures = now, 0, 0
+}
+
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.option.OnStateChange != nil {
+		b.option.OnStateChange(b.endpoint, from, to)
+	}
+}
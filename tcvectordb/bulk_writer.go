@@ -0,0 +1,421 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBulkWriterClosed the documents are written after BulkWriter.Close
+var ErrBulkWriterClosed = errors.New("bulk writer is closed")
+
+// BulkOperation the operation of a bulk batch
+type BulkOperation string
+
+const (
+	BulkUpsert BulkOperation = "upsert"
+	BulkDelete BulkOperation = "delete"
+	BulkUpdate BulkOperation = "update"
+)
+
+type BulkWriterOption struct {
+	// BatchSize: the max documents or ids of a request, default 100
+	BatchSize int
+	// MaxBatchBytes: the max estimated json size of the documents of an upsert request, default 4MB.
+	// A document larger than it is sent alone.
+	MaxBatchBytes int
+	// Workers: the max concurrent requests, default 4. The writes block when all workers are busy.
+	Workers int
+	// FlushInterval: send the pending documents periodically, default 0 means only when the batch is full,
+	// or on Flush and Close
+	FlushInterval time.Duration
+	// BuildIndex: passed to every upsert request
+	BuildIndex *bool
+	// DisableSplit: by default the upsert batch rejected by the server is split in halves and resent,
+	// to find out the documents which fail. Set true to fail the whole batch at once.
+	DisableSplit bool
+	// OnBatch: called after each batch is done, from the worker goroutines. It must not call the BulkWriter.
+	OnBatch func(result BulkBatchResult)
+}
+
+// BulkBatchResult the result of a request sent by the BulkWriter
+type BulkBatchResult struct {
+	Operation BulkOperation
+	// DocumentIds: the ids of the batch, empty for the update by filter
+	DocumentIds   []string
+	AffectedCount int
+	// FailedIds: the ids failed in the batch, all the DocumentIds unless the batch is split
+	FailedIds []string
+	// Err: the first error of the batch
+	Err error
+}
+
+// BulkResult the results of all the batches of the BulkWriter
+type BulkResult struct {
+	Batches       []BulkBatchResult
+	AffectedCount int
+	FailedIds     []string
+}
+
+// Err returns nil if all the batches succeed, or the error of the first failed batch with the failure count
+func (r *BulkResult) Err() error {
+	var failed int
+	var first error
+	for _, batch := range r.Batches {
+		if batch.Err != nil {
+			failed++
+			if first == nil {
+				first = batch.Err
+			}
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return fmt.Errorf("%d of %d bulk batches failed, %d documents failed: %w", failed, len(r.Batches), len(r.FailedIds), first)
+}
+
+// BulkWriter splits the writes of any number of documents into the batches, and sends them concurrently.
+// The batches of the same operation are not ordered. When the operation switches, eg: from upsert to delete,
+// the sent batches are waited before the new operation is sent, so a delete never overtakes an earlier upsert.
+type BulkWriter struct {
+	coll   *Collection
+	ctx    context.Context
+	option BulkWriterOption
+
+	mu      sync.Mutex
+	op      BulkOperation
+	docs    []Document
+	ids     []string
+	bytes   int
+	closed  bool
+	workers chan struct{}
+	wg      sync.WaitGroup
+	done    chan struct{}
+
+	resultMu sync.Mutex
+	result   BulkResult
+	// flushed: the number of the batches whose errors are returned by Flush
+	flushed int
+}
+
+// BulkWriter creates a BulkWriter of the collection, all the requests are sent with ctx.
+// Close it to send the pending documents and get the results.
+func (c *Collection) BulkWriter(ctx context.Context, option *BulkWriterOption) *BulkWriter {
+	w := &BulkWriter{coll: c, ctx: ctx, done: make(chan struct{})}
+	if option != nil {
+		w.option = *option
+	}
+	if w.option.BatchSize <= 0 {
+		w.option.BatchSize = 100
+	}
+	if w.option.MaxBatchBytes <= 0 {
+		w.option.MaxBatchBytes = 4 * 1024 * 1024
+	}
+	if w.option.Workers <= 0 {
+		w.option.Workers = 4
+	}
+	w.workers = make(chan struct{}, w.option.Workers)
+	if w.option.FlushInterval > 0 {
+		go w.flushLoop()
+	}
+	return w
+}
+
+func (w *BulkWriter) flushLoop() {
+	ticker := time.NewTicker(w.option.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			w.dispatchLocked()
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Upsert adds the documents to the pending upsert batch
+func (w *BulkWriter) Upsert(docs ...Document) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.switchLocked(BulkUpsert); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		size := estimateDocumentBytes(doc)
+		if len(w.docs) != 0 && (len(w.docs) >= w.option.BatchSize || w.bytes+size > w.option.MaxBatchBytes) {
+			w.dispatchLocked()
+		}
+		w.docs = append(w.docs, doc)
+		w.bytes += size
+	}
+	if len(w.docs) >= w.option.BatchSize || w.bytes >= w.option.MaxBatchBytes {
+		w.dispatchLocked()
+	}
+	return nil
+}
+
+// Delete adds the document ids to the pending delete batch
+func (w *BulkWriter) Delete(documentIds ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.switchLocked(BulkDelete); err != nil {
+		return err
+	}
+	for _, id := range documentIds {
+		w.ids = append(w.ids, id)
+		if len(w.ids) >= w.option.BatchSize {
+			w.dispatchLocked()
+		}
+	}
+	return nil
+}
+
+// Update sends the update, the QueryIds are split into the batches with the same update fields.
+// The update by QueryFilter only is sent as one request.
+func (w *BulkWriter) Update(param UpdateDocumentParams) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.switchLocked(BulkUpdate); err != nil {
+		return err
+	}
+	if len(param.QueryIds) == 0 {
+		w.dispatch(BulkUpdate, nil, nil, &param)
+		return nil
+	}
+	for start := 0; start < len(param.QueryIds); start += w.option.BatchSize {
+		end := start + w.option.BatchSize
+		if end > len(param.QueryIds) {
+			end = len(param.QueryIds)
+		}
+		batch := param
+		batch.QueryIds = param.QueryIds[start:end]
+		w.dispatch(BulkUpdate, nil, batch.QueryIds, &batch)
+	}
+	return nil
+}
+
+// Flush sends the pending documents and waits for all the sent batches,
+// it returns the joined errors of the batches failed since the last Flush
+func (w *BulkWriter) Flush() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrBulkWriterClosed
+	}
+	w.dispatchLocked()
+	w.mu.Unlock()
+	w.wg.Wait()
+
+	w.resultMu.Lock()
+	var errs []error
+	for _, batch := range w.result.Batches[w.flushed:] {
+		if batch.Err != nil {
+			errs = append(errs, batch.Err)
+		}
+	}
+	w.flushed = len(w.result.Batches)
+	w.resultMu.Unlock()
+	if len(errs) == 0 {
+		return w.ctx.Err()
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes the pending documents, and returns the results of all the batches.
+// The error is BulkResult.Err.
+func (w *BulkWriter) Close() (*BulkResult, error) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+		w.dispatchLocked()
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+
+	result := w.Result()
+	return result, result.Err()
+}
+
+// Result returns a snapshot of the results of the finished batches
+func (w *BulkWriter) Result() *BulkResult {
+	w.resultMu.Lock()
+	defer w.resultMu.Unlock()
+	return &BulkResult{
+		Batches:       append([]BulkBatchResult(nil), w.result.Batches...),
+		AffectedCount: w.result.AffectedCount,
+		FailedIds:     append([]string(nil), w.result.FailedIds...),
+	}
+}
+
+// switchLocked sends the pending batch of the other operation, and waits for all the sent batches
+func (w *BulkWriter) switchLocked(op BulkOperation) error {
+	if w.closed {
+		return ErrBulkWriterClosed
+	}
+	if w.op != op {
+		w.dispatchLocked()
+		w.wg.Wait()
+		w.op = op
+	}
+	return nil
+}
+
+// dispatchLocked sends the pending batch
+func (w *BulkWriter) dispatchLocked() {
+	switch {
+	case len(w.docs) != 0:
+		w.dispatch(BulkUpsert, w.docs, nil, nil)
+	case len(w.ids) != 0:
+		w.dispatch(BulkDelete, nil, w.ids, nil)
+	}
+	w.docs, w.ids, w.bytes = nil, nil, 0
+}
+
+// dispatch waits for an idle worker, it blocks the writes as the back pressure
+func (w *BulkWriter) dispatch(op BulkOperation, docs []Document, ids []string, update *UpdateDocumentParams) {
+	select {
+	case w.workers <- struct{}{}:
+	case <-w.ctx.Done():
+		w.finish(BulkBatchResult{Operation: op, DocumentIds: batchIds(docs, ids), FailedIds: batchIds(docs, ids), Err: w.ctx.Err()})
+		return
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() { <-w.workers }()
+		var result BulkBatchResult
+		switch op {
+		case BulkUpsert:
+			result = w.upsert(docs)
+		case BulkDelete:
+			result = BulkBatchResult{Operation: op, DocumentIds: ids}
+			res, err := w.coll.Delete(w.ctx, DeleteDocumentParams{DocumentIds: ids})
+			if err == nil {
+				result.AffectedCount = res.AffectedCount
+			}
+			result.setErr(err)
+		case BulkUpdate:
+			result = BulkBatchResult{Operation: op, DocumentIds: ids}
+			res, err := w.coll.Update(w.ctx, *update)
+			if err == nil {
+				result.AffectedCount = res.AffectedCount
+			}
+			result.setErr(err)
+		}
+		w.finish(result)
+	}()
+}
+
+// upsert sends the documents, and splits the batch rejected by the server to find out the failed documents
+func (w *BulkWriter) upsert(docs []Document) BulkBatchResult {
+	result := BulkBatchResult{Operation: BulkUpsert, DocumentIds: batchIds(docs, nil)}
+	res, err := w.coll.Upsert(w.ctx, docs, &UpsertDocumentParams{BuildIndex: w.option.BuildIndex})
+	if err == nil {
+		result.AffectedCount = res.AffectedCount
+		return result
+	}
+	if len(docs) == 1 || w.option.DisableSplit || !splittable(err) {
+		result.setErr(err)
+		return result
+	}
+	for _, half := range [][]Document{docs[:len(docs)/2], docs[len(docs)/2:]} {
+		sub := w.upsert(half)
+		result.AffectedCount += sub.AffectedCount
+		result.FailedIds = append(result.FailedIds, sub.FailedIds...)
+		if result.Err == nil {
+			result.Err = sub.Err
+		}
+	}
+	return result
+}
+
+// splittable judges the failure is a rejection of the payload, which could be caused by some documents
+// of the batch, or by the batch size. The transient failures such as 5xx and 429 are left to the retry policy,
+// splitting them would only multiply the requests to a server in trouble.
+func splittable(err error) bool {
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || DefaultRetryable(err) ||
+		errors.Is(err, ErrDatabaseNotFound) || errors.Is(err, ErrCollectionNotFound) {
+		return false
+	}
+	switch serverErr.StatusCode / 100 {
+	case 0, 2:
+		// the code in the response body, or on the grpc transport
+		return serverErr.Code != 0
+	case 4:
+		switch serverErr.StatusCode {
+		case 401, 403, 404, 408, 429:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func (r *BulkBatchResult) setErr(err error) {
+	if err != nil {
+		r.Err = err
+		r.FailedIds = r.DocumentIds
+	}
+}
+
+func (w *BulkWriter) finish(result BulkBatchResult) {
+	w.resultMu.Lock()
+	w.result.Batches = append(w.result.Batches, result)
+	w.result.AffectedCount += result.AffectedCount
+	w.result.FailedIds = append(w.result.FailedIds, result.FailedIds...)
+	w.resultMu.Unlock()
+	if w.option.OnBatch != nil {
+		w.option.OnBatch(result)
+	}
+}
+
+func batchIds(docs []Document, ids []string) []string {
+	if len(docs) == 0 {
+		return ids
+	}
+	out := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, doc.Id)
+	}
+	return out
+}
+
+// estimateDocumentBytes estimates the json size of the document in the request
+func estimateDocumentBytes(doc Document) int {
+	size := len(doc.Id) + 16*len(doc.Vector) + 32*len(doc.SparseVector) + 64
+	for k, v := range doc.Fields {
+		body, err := json.Marshal(v.Val)
+		if err != nil {
+			body = []byte(fmt.Sprint(v.Val))
+		}
+		size += len(k) + len(body) + 4
+	}
+	return size
+}
@@ -0,0 +1,170 @@
+package tcvectordb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulkWriter(t *testing.T) {
+	var upserts, deletes, updates int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Documents []map[string]interface{} `json:"documents"`
+			Query     struct {
+				DocumentIds []string `json:"documentIds"`
+			} `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/document/upsert":
+			atomic.AddInt32(&upserts, 1)
+			for _, doc := range req.Documents {
+				if doc["id"] == "bad" {
+					w.Write([]byte(`{"code":14000,"msg":"invalid document"}`))
+					return
+				}
+			}
+			fmt.Fprintf(w, `{"code":0,"affectedCount":%d}`, len(req.Documents))
+		case "/document/delete":
+			atomic.AddInt32(&deletes, 1)
+			fmt.Fprintf(w, `{"code":0,"affectedCount":%d}`, len(req.Query.DocumentIds))
+		case "/document/update":
+			atomic.AddInt32(&updates, 1)
+			fmt.Fprintf(w, `{"code":0,"affectedCount":%d}`, len(req.Query.DocumentIds))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := cli.Database("db").Collection("coll")
+	writer := coll.BulkWriter(context.Background(), &BulkWriterOption{BatchSize: 10, Workers: 2})
+	var docs []Document
+	var ids []string
+	for i := 0; i < 25; i++ {
+		id := fmt.Sprintf("%04d", i)
+		if i == 13 {
+			id = "bad"
+		}
+		docs = append(docs, Document{Id: id, Vector: []float32{1, 0}})
+		ids = append(ids, id)
+	}
+	if err = writer.Upsert(docs...); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Flush(); err == nil || !strings.Contains(err.Error(), "invalid document") {
+		t.Fatalf("expect Flush to return the failure of the bad document, got %v", err)
+	}
+	if err = writer.Delete(ids...); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Update(UpdateDocumentParams{QueryIds: ids, UpdateFields: map[string]Field{"page": {Val: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := writer.Close()
+	if err == nil || !strings.Contains(err.Error(), "invalid document") {
+		t.Fatalf("expect the failure of the bad document, got %v", err)
+	}
+	if len(result.FailedIds) != 1 || result.FailedIds[0] != "bad" {
+		t.Fatalf("expect only the bad document failed, got %v", result.FailedIds)
+	}
+	if result.AffectedCount != 24+25+25 || len(result.Batches) != 9 {
+		t.Fatalf("unexpected bulk result, affected %d, %d batches", result.AffectedCount, len(result.Batches))
+	}
+	// the batch with the bad document is split in halves 4 times: 10 -> 5 -> 3 -> 2 -> 1
+	if upserts != 3+8 || deletes != 3 || updates != 3 {
+		t.Fatalf("unexpected requests: %d upserts, %d deletes, %d updates", upserts, deletes, updates)
+	}
+	if err = writer.Upsert(docs[0]); !errors.Is(err, ErrBulkWriterClosed) {
+		t.Fatalf("expect ErrBulkWriterClosed, got %v", err)
+	}
+
+	atomic.StoreInt32(&upserts, 0)
+	writer = coll.BulkWriter(context.Background(), &BulkWriterOption{BatchSize: 10, FlushInterval: 20 * time.Millisecond})
+	if err = writer.Upsert(docs[0]); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&upserts) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&upserts) != 1 {
+		t.Fatal("the pending document is not flushed by the timer")
+	}
+	if _, err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBulkWriterTransientErrorAndOrder(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var status int32 = http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/document/upsert" {
+			// the slow upsert must not be overtaken by the delete
+			time.Sleep(50 * time.Millisecond)
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if code := atomic.LoadInt32(&status); code != http.StatusOK {
+			w.WriteHeader(int(code))
+			w.Write([]byte(`{"code":1,"msg":"busy"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"affectedCount":1}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := cli.Database("db").Collection("coll")
+	for _, code := range []int32{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		atomic.StoreInt32(&status, code)
+		mu.Lock()
+		paths = nil
+		mu.Unlock()
+		writer := coll.BulkWriter(context.Background(), &BulkWriterOption{BatchSize: 10})
+		for i := 0; i < 10; i++ {
+			writer.Upsert(Document{Id: fmt.Sprintf("%04d", i), Vector: []float32{1, 0}})
+		}
+		result, err := writer.Close()
+		if err == nil || len(result.FailedIds) != 10 || len(paths) != 1 {
+			t.Fatalf("%d: the transient failure is split, %d requests, %v", code, len(paths), err)
+		}
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	mu.Lock()
+	paths = nil
+	mu.Unlock()
+	writer := coll.BulkWriter(context.Background(), nil)
+	if err = writer.Upsert(Document{Id: "0001", Vector: []float32{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Delete("0001"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "/document/upsert" || paths[1] != "/document/delete" {
+		t.Fatalf("the delete overtakes the upsert: %v", paths)
+	}
+}
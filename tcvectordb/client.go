@@ -21,17 +21,18 @@ package tcvectordb
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/database"
+	"google.golang.org/grpc"
 )
 
 // SdkClient the http client interface
@@ -43,8 +44,20 @@ type SdkClient interface {
 	Close()
 }
 
+// Pinger is implemented by Client and RpcClient, it is not a part of SdkClient,
+// so the existing SdkClient implementations are still valid
+type Pinger interface {
+	// Ping checks the server is reachable and the credential is accepted
+	Ping(ctx context.Context) error
+	// WaitReady blocks until Ping succeeds or ctx is done
+	WaitReady(ctx context.Context) error
+}
+
+var _ Pinger = &Client{}
+var _ Pinger = &RpcClient{}
+
 type ClientOption struct {
-	// Timeout: default 5s
+	// Timeout: default 5s, used for the requests whose context has no deadline
 	Timeout time.Duration
 	// MaxIdldConnPerHost: default 2
 	MaxIdldConnPerHost int
@@ -54,17 +67,48 @@ type ClientOption struct {
 	ReadConsistency ReadConsistency
 	// Transport: default: http.Transport
 	Transport http.RoundTripper
+	// TLS: the tls settings for https urls and the grpc transport, ignored by http if Transport is set.
+	// default nil verifies the server certificate with the system roots on both transports
+	TLS *TLSOption
+	// RetryPolicy: default retry the read requests at most 3 times on transient errors
+	RetryPolicy *RetryPolicy
+	// LoadBalance: how to spread the read requests when the url has several comma separated endpoints,
+	// default: RoundRobin. The write requests always use the first endpoint, and fail while it is unreachable.
+	LoadBalance LoadBalancePolicy
+	// EjectDuration: how long an endpoint is ejected after a connection error, default 30s
+	EjectDuration time.Duration
+	// Logger: default print with the standard library log. The debug events are sent when Debug(true) is set
+	Logger Logger
+	// Middlewares: wrap every request in order, the first one is the outermost
+	Middlewares []Middleware
+	// RateLimits: the client side rate and concurrency limits by operation class, default no limit
+	RateLimits map[OperationClass]RateLimit
+	// CircuitBreaker: the circuit breaker of each endpoint, default nil means disabled
+	CircuitBreaker *CircuitBreakerOption
+	// Credentials: consulted on every request, the username and key params are ignored if it is set
+	Credentials CredentialProvider
+	// WarmUp: pre-establish the connections in background when the client is created, default false.
+	// The http client opens MaxIdldConnPerHost idle connections to each endpoint, the grpc client connects each endpoint.
+	WarmUp bool
+	// GrpcDialOptions: the extra grpc dial options of RpcClient, eg: a custom dialer for the tests
+	GrpcDialOptions []grpc.DialOption
+	// ValidateSchema: check the filters and OutputFields of Search, Query, Delete and Update against the indexes
+	// before sending, each collection is described once a minute and cached, default false. The output fields
+	// without index are rejected, keep it false for the collections with such fields. See Collection.Validate
+	ValidateSchema bool
 }
 type Client struct {
 	DatabaseInterface
 	FlatInterface
 
-	cli      *http.Client
-	url      string
-	username string
-	key      string
-	option   ClientOption
-	debug    bool
+	cli         *http.Client
+	url         string
+	endpoints   *endpointSelector
+	invoker     Invoker
+	credentials CredentialProvider
+	schemas     schemaCache
+	option      ClientOption
+	debug       bool
 }
 
 type CommmonResponse struct {
@@ -79,6 +123,8 @@ var defaultOption = ClientOption{
 	MaxIdldConnPerHost: 2,
 	IdleConnTimeout:    time.Minute,
 	ReadConsistency:    api.EventualConsistency,
+	LoadBalance:        RoundRobin,
+	EjectDuration:      30 * time.Second,
 }
 
 func NewClient(url, username, key string, option *ClientOption) (*Client, error) {
@@ -88,36 +134,48 @@ func NewClient(url, username, key string, option *ClientOption) (*Client, error)
 	return newClient(url, username, key, optionMerge(*option))
 }
 
-// newClient new http client with url, username and api key
+// newClient new http client with url, username and api key, or the option.Credentials.
+// The url could contain several endpoints separated by comma, eg: http://10.0.0.1,http://10.0.0.2
 func newClient(url, username, key string, option ClientOption) (*Client, error) {
-	if !strings.HasPrefix(url, "http") {
+	endpoints := splitEndpoints(url)
+	if len(endpoints) == 0 {
 		return nil, errors.Errorf("invalid url param with: %s", url)
 	}
-	if username == "" || key == "" {
-		return nil, errors.New("username or key is empty")
+	for _, endpoint := range endpoints {
+		if !strings.HasPrefix(endpoint, "http") {
+			return nil, errors.Errorf("invalid url param with: %s", endpoint)
+		}
+	}
+	credentials, err := newCredentials(username, key, option.Credentials)
+	if err != nil {
+		return nil, err
 	}
 
 	cli := new(Client)
 	cli.url = url
-	cli.username = username
-	cli.key = key
+	cli.credentials = credentials
 	cli.debug = false
 
 	cli.option = optionMerge(option)
+	cli.endpoints = newEndpointSelector(endpoints, cli.option.LoadBalance, cli.option.EjectDuration,
+		cli.option.CircuitBreaker)
+	limiter := newRateLimiter(cli.option.RateLimits)
+	cli.invoker = chainMiddlewares(limiter.appendMiddleware(cli.option.Middlewares), cli.request)
 
 	cli.cli = new(http.Client)
 	if option.Transport != nil {
 		cli.cli.Transport = option.Transport
 	} else {
+		tlsConfig, err := cli.option.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
 		cli.cli.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+			TLSClientConfig:     tlsConfig,
 			MaxIdleConnsPerHost: cli.option.MaxIdldConnPerHost,
 			IdleConnTimeout:     cli.option.IdleConnTimeout,
 		}
 	}
-	cli.cli.Timeout = cli.option.Timeout
 
 	databaseImpl := new(implementerDatabase)
 	databaseImpl.SdkClient = cli
@@ -126,15 +184,20 @@ func newClient(url, username, key string, option ClientOption) (*Client, error)
 
 	cli.DatabaseInterface = databaseImpl
 	cli.FlatInterface = flatImpl
+
+	if cli.option.WarmUp {
+		go cli.warmUp()
+	}
 	return cli, nil
 }
 
-// Request do request for client
+// Request do request for client, it goes through the middlewares
 func (c *Client) Request(ctx context.Context, req, res interface{}) error {
-	var (
-		method = api.Method(req)
-		path   = api.Path(req)
-	)
+	return c.invoker(ctx, api.Path(req), req, res)
+}
+
+func (c *Client) request(ctx context.Context, path string, req, res interface{}) error {
+	method := api.Method(req)
 	reqBody := bytes.NewBuffer(nil)
 	encoder := json.NewEncoder(reqBody)
 	encoder.SetEscapeHTML(false)
@@ -143,30 +206,61 @@ func (c *Client) Request(ctx context.Context, req, res interface{}) error {
 		return fmt.Errorf("%w, %#v", err, req)
 	}
 
-	request, err := http.NewRequest(strings.ToUpper(method), c.url+path, reqBody)
-	if err != nil {
-		return err
+	// the per-call deadline comes from ctx, the client timeout is only used as a default
+	if _, ok := ctx.Deadline(); !ok && c.option.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.option.Timeout)
+		defer cancel()
 	}
 
+	var debugLogger Logger
 	if c.debug {
-		log.Printf("[DEBUG] REQUEST, Method: %s, Path: %s, Body: %s", method, path, strings.TrimSpace(reqBody.String()))
+		debugLogger = c.option.Logger
+		debugLogger.Log(ctx, LogLevelDebug, "request", "method", method, "path", path,
+			"size", reqBody.Len(), "body", redactBody(reqBody.Bytes()))
 	}
 
-	auth := fmt.Sprintf("Bearer account=%s&api_key=%s", c.username, c.key)
-	request.Header.Add("Authorization", auth)
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Sdk-Version", SDKVersion)
+	return withRetry(ctx, c.option.RetryPolicy, debugLogger, path, func(ctx context.Context) error {
+		return c.endpoints.do(path, func(e *endpoint) error {
+			return c.do(ctx, e.url, method, path, reqBody.Bytes(), res)
+		})
+	})
+}
+
+// do send one http request to the endpoint and decode the response into res
+func (c *Client) do(ctx context.Context, url, method, path string, body []byte, res interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for k, values := range headersFromContext(ctx) {
+		for _, v := range values {
+			request.Header.Add(k, v)
+		}
+	}
+	cred, err := c.credentials.Credential(ctx)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", cred.authorization())
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Sdk-Version", SDKVersion)
+	start := time.Now()
 	response, err := c.cli.Do(request)
 	if err != nil {
+		if c.debug {
+			c.option.Logger.Log(ctx, LogLevelDebug, "response", "method", method, "path", path,
+				"endpoint", url, "headers", redactHeader(request.Header), "latency", time.Since(start), "error", err)
+		}
 		return err
 	}
-	return c.handleResponse(ctx, response, res)
+	return c.handleResponse(ctx, method, path, response, res, start)
 }
 
-// WithTimeout set client timeout
+// WithTimeout set client timeout, which is used when the request context has no deadline
 func (c *Client) WithTimeout(d time.Duration) {
 	c.option.Timeout = d
-	c.cli.Timeout = d
 }
 
 // Debug set debug mode to show the request and response info
@@ -174,17 +268,27 @@ func (c *Client) Debug(v bool) {
 	c.debug = v
 }
 
-func (c *Client) handleResponse(ctx context.Context, res *http.Response, out interface{}) error {
+func (c *Client) handleResponse(ctx context.Context, method, path string, res *http.Response, out interface{}, start time.Time) error {
+	defer res.Body.Close()
 	responseBytes, err := io.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
 	if c.debug {
-		log.Printf("[DEBUG] RESPONSE: %d %s", res.StatusCode, string(responseBytes))
+		var commenRes CommmonResponse
+		_ = json.Unmarshal(responseBytes, &commenRes)
+		c.option.Logger.Log(ctx, LogLevelDebug, "response", "method", method, "path", path,
+			"latency", time.Since(start), "status", res.StatusCode,
+			"code", commenRes.Code, "size", len(responseBytes), "body", redactBody(responseBytes))
 	}
 	if res.StatusCode/100 != 2 {
-		return errors.Errorf("response code is %d, %s", res.StatusCode, string(responseBytes))
+		serverErr := &ServerError{StatusCode: res.StatusCode, Message: string(responseBytes), Path: path}
+		var commenRes CommmonResponse
+		if json.Unmarshal(responseBytes, &commenRes) == nil && commenRes.Code != 0 {
+			serverErr.Code = commenRes.Code
+			serverErr.Message = commenRes.Msg
+		}
+		return serverErr
 	}
 
 	if !json.Valid(responseBytes) {
@@ -197,7 +301,7 @@ func (c *Client) handleResponse(ctx context.Context, res *http.Response, out int
 	}
 
 	if commenRes.Code != 0 {
-		return errors.Errorf("code: %d, message: %s", commenRes.Code, commenRes.Msg)
+		return &ServerError{StatusCode: res.StatusCode, Code: commenRes.Code, Message: commenRes.Msg, Path: path}
 	}
 
 	if err := json.Unmarshal(responseBytes, &out); err != nil {
@@ -206,6 +310,71 @@ func (c *Client) handleResponse(ctx context.Context, res *http.Response, out int
 	return nil
 }
 
+func (c *Client) validateSchema(ctx context.Context, database, collection, cond string, outputFields []string) error {
+	if !c.option.ValidateSchema {
+		return nil
+	}
+	return c.schemas.validate(ctx, c.Database(database), collection, cond, outputFields)
+}
+
+func (c *Client) forgetSchema(database, collection string) {
+	c.schemas.forget(database, collection)
+}
+
+// Ping do a cheap authenticated request to the server
+func (c *Client) Ping(ctx context.Context) error {
+	return c.Request(ctx, new(database.ListReq), new(database.ListRes))
+}
+
+// WaitReady blocks until Ping succeeds or ctx is done
+func (c *Client) WaitReady(ctx context.Context) error {
+	return waitReady(ctx, c.Ping)
+}
+
+// warmUp opens MaxIdldConnPerHost connections to each endpoint concurrently,
+// they are kept in the idle pool for the later requests
+func (c *Client) warmUp() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.option.Timeout)
+	defer cancel()
+	req := new(database.ListReq)
+	method, path := api.Method(req), api.Path(req)
+
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints.endpoints {
+		for i := 0; i < c.option.MaxIdldConnPerHost; i++ {
+			wg.Add(1)
+			go func(url string) {
+				defer wg.Done()
+				if err := c.do(ctx, url, method, path, []byte("{}"), new(database.ListRes)); err != nil {
+					c.option.Logger.Log(ctx, LogLevelWarn, "warm up connection failed", "endpoint", url, "error", err)
+				}
+			}(e.url)
+		}
+	}
+	wg.Wait()
+}
+
+// waitReady calls ping with backoff until it succeeds or ctx is done
+func waitReady(ctx context.Context, ping func(ctx context.Context) error) error {
+	backoff := 100 * time.Millisecond
+	for {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w, last error: %v", ctx.Err(), err)
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > 2*time.Second {
+			backoff = 2 * time.Second
+		}
+	}
+}
+
 // Close wrap http.Client.CloseIdleConnections
 func (c *Client) Close() {
 	c.cli.CloseIdleConnections()
@@ -228,5 +397,24 @@ func optionMerge(option ClientOption) ClientOption {
 	if option.ReadConsistency == "" {
 		option.ReadConsistency = defaultOption.ReadConsistency
 	}
+	if option.LoadBalance == "" {
+		option.LoadBalance = defaultOption.LoadBalance
+	}
+	if option.EjectDuration == 0 {
+		option.EjectDuration = defaultOption.EjectDuration
+	}
+	if option.Logger == nil {
+		option.Logger = defaultLogger
+	}
+	policy := defaultRetryPolicy
+	if option.RetryPolicy != nil {
+		policy = *option.RetryPolicy
+	}
+	policy = retryPolicyMerge(policy)
+	option.RetryPolicy = &policy
+	if option.CircuitBreaker != nil {
+		breaker := circuitBreakerMerge(*option.CircuitBreaker)
+		option.CircuitBreaker = &breaker
+	}
 	return option
 }
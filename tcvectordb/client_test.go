@@ -0,0 +1,525 @@
+package tcvectordb
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRequestContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{Timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = cli.ListDatabase(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("request was not abandoned after the context deadline")
+	}
+}
+
+func TestClientRequestRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":0,"databases":["db"]}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{
+		RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := cli.ListDatabase(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Databases) != 1 || atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("unexpected result %+v after %d calls", res, calls)
+	}
+
+	// write requests are not retried unless RetryWrites is set
+	atomic.StoreInt32(&calls, 0)
+	if _, err = cli.CreateDatabase(context.Background(), "db"); err == nil {
+		t.Fatal("expect error for create database")
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("write request was sent %d times", calls)
+	}
+
+	// the retries are debug events, which are not logged without Debug(true)
+	var events []string
+	cli, err = NewClient(srv.URL, "root", "key", &ClientOption{
+		RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+		Logger: LoggerFunc(func(ctx context.Context, level LogLevel, msg string, keyvals ...interface{}) {
+			events = append(events, level.String()+" "+msg)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&calls, 0)
+	if _, err = cli.ListDatabase(context.Background()); err != nil || len(events) != 0 {
+		t.Fatalf("unexpected events %v, %v", events, err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicyMerge(RetryPolicy{InitialBackoff: 10 * time.Millisecond, Multiplier: 1, NoJitter: true})
+	for retry := 1; retry <= 3; retry++ {
+		if d := policy.backoff(retry); d != 10*time.Millisecond {
+			t.Fatalf("retry %d: expect the constant backoff, got %v", retry, d)
+		}
+	}
+	policy = retryPolicyMerge(RetryPolicy{})
+	if policy.Jitter != defaultRetryPolicy.Jitter || policy.Multiplier != defaultRetryPolicy.Multiplier {
+		t.Fatalf("unexpected default policy %+v", policy)
+	}
+}
+
+func TestClientServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/collection/describe":
+			w.Write([]byte(`{"code":15302,"msg":"collection not exist"}`))
+		case "/database/drop":
+			w.Write([]byte(`{"code":15301,"msg":"database not exist"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":1,"msg":"bad request"}`))
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cli.Database("db").DescribeCollection(context.Background(), "coll")
+	if !errors.Is(err, ErrCollectionNotFound) {
+		t.Fatalf("expect ErrCollectionNotFound, got %v", err)
+	}
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Code != ERR_UNDEFINED_COLLECTION || serverErr.Path != "/collection/describe" {
+		t.Fatalf("unexpected server error %#v", serverErr)
+	}
+
+	exists, err := cli.Database("db").ExistsCollection(context.Background(), "coll")
+	if err != nil || exists {
+		t.Fatalf("unexpected exists result %v, %v", exists, err)
+	}
+	if _, err = cli.DropDatabase(context.Background(), "db"); err != nil {
+		t.Fatalf("drop a not exist database should succeed, got %v", err)
+	}
+
+	_, err = cli.CreateDatabase(context.Background(), "db")
+	if !errors.As(err, &serverErr) || serverErr.StatusCode != http.StatusBadRequest || serverErr.Message != "bad request" {
+		t.Fatalf("unexpected server error %v", err)
+	}
+}
+
+func TestServerErrorIs(t *testing.T) {
+	cases := []struct {
+		err    *ServerError
+		target error
+		want   bool
+	}{
+		{&ServerError{Code: ERR_UNDEFINED_DATABASE, Message: "database not exist", Path: "/collection/describe"}, ErrDatabaseNotFound, true},
+		{&ServerError{Code: ERR_UNDEFINED_DATABASE, Message: "database not exist", Path: "/collection/describe"}, ErrCollectionNotFound, false},
+		{&ServerError{Code: 1, Message: "database db not exist", Path: "/collection/describe"}, ErrCollectionNotFound, false},
+		{&ServerError{Code: 1, Message: "database db not exist", Path: "/collection/describe"}, ErrDatabaseNotFound, true},
+		{&ServerError{Code: 1, Message: "collection db.coll not exist", Path: "/document/query"}, ErrCollectionNotFound, true},
+		{&ServerError{Code: 1, Message: "can not find collectionView db.view"}, ErrCollectionNotFound, true},
+	}
+	for i, c := range cases {
+		if got := errors.Is(c.err, c.target); got != c.want {
+			t.Errorf("case %d: errors.Is(%v, %v) = %v, want %v", i, c.err, c.target, got, c.want)
+		}
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{
+		TLS:         &TLSOption{CACertPEM: caPEM},
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.ListDatabase(context.Background()); err != nil {
+		t.Fatalf("request with the trusted ca failed: %v", err)
+	}
+
+	// the server is verified by default, as the grpc transport does
+	for _, option := range []*TLSOption{nil, {}} {
+		cli, err = NewClient(srv.URL, "root", "key", &ClientOption{
+			TLS:         option,
+			RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = cli.ListDatabase(context.Background()); err == nil {
+			t.Fatalf("expect certificate verification error with %+v", option)
+		}
+	}
+
+	cli, err = NewClient(srv.URL, "root", "key", &ClientOption{
+		TLS:         &TLSOption{InsecureSkipVerify: true},
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.ListDatabase(context.Background()); err != nil {
+		t.Fatalf("request skipping the verification failed: %v", err)
+	}
+}
+
+func TestClientEndpointFailover(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	var calls int32
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer live.Close()
+
+	cli, err := NewClient(dead.URL+","+live.URL, "root", "key", &ClientOption{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// writes are pinned to the primary, and fail without failover
+	if _, err = cli.CreateDatabase(context.Background(), "db"); err == nil {
+		t.Fatal("expect connection error from the primary endpoint")
+	}
+	// the primary is ejected now, the reads go to the healthy endpoint
+	for i := 0; i < 3; i++ {
+		if _, err = cli.ListDatabase(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the writes stay on the ejected primary
+	if _, err = cli.CreateDatabase(context.Background(), "db"); !isConnectionError(err) {
+		t.Fatalf("expect connection error from the ejected primary, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("expect 3 calls on the healthy endpoint, got %d", calls)
+	}
+}
+
+func TestClientDebugLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0,"documents":[[{"id":"0001","score":0.9}]]}`))
+	}))
+	defer srv.Close()
+
+	var lines []string
+	logger := LoggerFunc(func(ctx context.Context, level LogLevel, msg string, keyvals ...interface{}) {
+		lines = append(lines, fmt.Sprintln(append([]interface{}{level, msg}, keyvals...)...))
+	})
+	cli, err := NewClient(srv.URL, "root", "secret-key", &ClientOption{Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.Debug(true)
+	vector := make([]float32, 768)
+	if _, err = cli.Search(context.Background(), "db", "coll", [][]float32{vector}); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expect request and response events, got %v", lines)
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "secret-key") || len(line) > 1024 {
+			t.Fatalf("the log is not redacted: %s", line)
+		}
+	}
+	if !strings.Contains(lines[0], "(768 items)") || !strings.Contains(lines[1], "status 200") {
+		t.Fatalf("unexpected log events: %v", lines)
+	}
+}
+
+func TestClientMiddlewares(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	metrics := NewLatencyMetrics(nil)
+	var paths []string
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{
+		Middlewares: []Middleware{
+			metrics.Middleware(),
+			TraceContextMiddleware(nil),
+			RequestIDMiddleware("", func() string { return "req-1" }),
+			func(ctx context.Context, path string, req, res interface{}, next Invoker) error {
+				paths = append(paths, path)
+				return next(WithHeader(ctx, "X-Custom", "v"), path, req, res)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.ListDatabase(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != "/database/list" {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	if header.Get(RequestIDHeader) != "req-1" || header.Get("X-Custom") != "v" || len(header.Get(TraceParentHeader)) != 55 {
+		t.Fatalf("unexpected headers %v", header)
+	}
+	if h := metrics.Snapshot()["/database/list"]; h.Count != 1 || h.Errors != 0 {
+		t.Fatalf("unexpected histogram %+v", h)
+	}
+}
+
+func TestClientRateLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{
+		RateLimits: map[OperationClass]RateLimit{
+			OperationUpsert: {RequestsPerSecond: 1, FailFast: true},
+			OperationSearch: {RequestsPerSecond: 20, Burst: 1, MaxInFlight: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []Document{{Id: "0001", Vector: []float32{0.1}}}
+	if _, err = cli.Upsert(context.Background(), "db", "coll", docs); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cli.Upsert(context.Background(), "db", "coll", docs)
+	var limitErr *RateLimitError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &limitErr) || limitErr.Class != OperationUpsert {
+		t.Fatalf("expect rate limit error, got %v", err)
+	}
+	if _, err = cli.Query(context.Background(), "db", "coll", []string{"0001"}); err != nil {
+		t.Fatalf("query is not limited, got %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err = cli.Search(context.Background(), "db", "coll", [][]float32{{0.1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) < 90*time.Millisecond {
+		t.Fatalf("search requests are not throttled")
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls, healthy int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	var changes []string
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker: &CircuitBreakerOption{
+			ConsecutiveFailures: 2,
+			OpenTimeout:         50 * time.Millisecond,
+			OnStateChange: func(endpoint string, from, to CircuitState) {
+				changes = append(changes, fmt.Sprintf("%s->%s", from, to))
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err = cli.ListDatabase(context.Background())
+	}
+	if !errors.Is(err, ErrCircuitOpen) || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("expect the breaker to reject the third call, got %v after %d calls", err, calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&healthy, 1)
+	if _, err = cli.ListDatabase(context.Background()); err != nil {
+		t.Fatalf("the half-open probe failed: %v", err)
+	}
+	expect := "closed->open,open->half-open,half-open->closed"
+	if strings.Join(changes, ",") != expect {
+		t.Fatalf("unexpected state changes %v", changes)
+	}
+}
+
+func TestClientCredentialProvider(t *testing.T) {
+	var auth atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "credential.json")
+	if err := os.WriteFile(path, []byte(`{"username":"root","key":"key-1"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cli, err := NewClient(srv.URL, "", "", &ClientOption{Credentials: FileCredentials(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.ListDatabase(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if auth.Load() != "Bearer account=root&api_key=key-1" {
+		t.Fatalf("unexpected authorization %v", auth.Load())
+	}
+
+	if err = os.WriteFile(path, []byte("root\nkey-rotated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.ListDatabase(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if auth.Load() != "Bearer account=root&api_key=key-rotated" {
+		t.Fatalf("the rotated key is not used, got %v", auth.Load())
+	}
+
+	if _, err = NewClient(srv.URL, "", "", nil); err == nil {
+		t.Fatal("expect error for empty username and key")
+	}
+}
+
+func TestClientPingWarmUp(t *testing.T) {
+	var calls, conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) <= 4 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{
+		WarmUp:      true,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the warm up requests are sent in background
+	for start := time.Now(); atomic.LoadInt32(&calls) < 2; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("the connections are not warmed up")
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 2 {
+		t.Fatalf("expect 2 warmed up connections, got %d", n)
+	}
+	if err = cli.Ping(context.Background()); err == nil {
+		t.Fatal("expect ping error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = cli.WaitReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientSchemaCache(t *testing.T) {
+	var describeCalls, queryCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/collection/describe" {
+			atomic.AddInt32(&describeCalls, 1)
+			w.Write([]byte(`{"code":1,"msg":"server busy"}`))
+			return
+		}
+		atomic.AddInt32(&queryCalls, 1)
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, "root", "key", &ClientOption{ValidateSchema: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := func() {
+		if _, err := cli.Query(context.Background(), "db", "coll", nil, &QueryDocumentParams{Filter: NewFilter(`a = 1`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the describe failure skips the validation, and is cached for a while
+	query()
+	query()
+	if atomic.LoadInt32(&describeCalls) != 1 || atomic.LoadInt32(&queryCalls) != 2 {
+		t.Fatalf("unexpected calls: describe %d, query %d", describeCalls, queryCalls)
+	}
+	cli.schemas.mu.Lock()
+	cli.schemas.entries[[2]string{"db", "coll"}].expires = time.Now()
+	cli.schemas.mu.Unlock()
+	query()
+	if atomic.LoadInt32(&describeCalls) != 2 {
+		t.Fatalf("the expired schema is not described again, describe %d", describeCalls)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credential the account and api key of the vectordb instance
+type Credential struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+}
+
+func (c Credential) authorization() string {
+	return fmt.Sprintf("Bearer account=%s&api_key=%s", c.Username, c.Key)
+}
+
+// CredentialProvider is consulted on every request, so the key could be rotated without rebuilding the client
+type CredentialProvider interface {
+	Credential(ctx context.Context) (Credential, error)
+}
+
+// CredentialProviderFunc adapts a function to CredentialProvider
+type CredentialProviderFunc func(ctx context.Context) (Credential, error)
+
+func (f CredentialProviderFunc) Credential(ctx context.Context) (Credential, error) {
+	return f(ctx)
+}
+
+// StaticCredentials returns the fixed username and key
+func StaticCredentials(username, key string) CredentialProvider {
+	return CredentialProviderFunc(func(ctx context.Context) (Credential, error) {
+		return Credential{Username: username, Key: key}, nil
+	})
+}
+
+const (
+	DefaultUsernameEnv = "VECTORDB_USERNAME"
+	DefaultKeyEnv      = "VECTORDB_KEY"
+)
+
+// EnvCredentials reads the username and key from the environment variables on every request.
+// Empty names mean VECTORDB_USERNAME and VECTORDB_KEY.
+func EnvCredentials(usernameEnv, keyEnv string) CredentialProvider {
+	if usernameEnv == "" {
+		usernameEnv = DefaultUsernameEnv
+	}
+	if keyEnv == "" {
+		keyEnv = DefaultKeyEnv
+	}
+	return CredentialProviderFunc(func(ctx context.Context) (Credential, error) {
+		cred := Credential{Username: os.Getenv(usernameEnv), Key: os.Getenv(keyEnv)}
+		if cred.Username == "" || cred.Key == "" {
+			return cred, fmt.Errorf("environment variable %s or %s is empty", usernameEnv, keyEnv)
+		}
+		return cred, nil
+	})
+}
+
+// FileCredentials reads the credential from the file, which is re-read when its size or modify time changes.
+// The file is a json object like {"username":"root","key":"xxx"}, or two lines with the username and the key.
+func FileCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path}
+}
+
+type fileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	cred    Credential
+}
+
+func (f *fileCredentials) Credential(ctx context.Context) (Credential, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credential{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cred.Key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.cred, nil
+	}
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return Credential{}, err
+	}
+	cred, err := parseCredential(content)
+	if err != nil {
+		return Credential{}, fmt.Errorf("invalid credential file %s: %w", f.path, err)
+	}
+	f.cred, f.modTime, f.size = cred, info.ModTime(), info.Size()
+	return cred, nil
+}
+
+func parseCredential(content []byte) (Credential, error) {
+	var cred Credential
+	text := strings.TrimSpace(string(content))
+	if strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal([]byte(text), &cred); err != nil {
+			return cred, err
+		}
+	} else if lines := strings.Split(text, "\n"); len(lines) == 2 {
+		cred.Username = strings.TrimSpace(lines[0])
+		cred.Key = strings.TrimSpace(lines[1])
+	}
+	if cred.Username == "" || cred.Key == "" {
+		return cred, errors.New("username or key is empty")
+	}
+	return cred, nil
+}
+
+// newCredentials returns the provider if set, otherwise the static username and key
+func newCredentials(username, key string, provider CredentialProvider) (CredentialProvider, error) {
+	if provider != nil {
+		return provider, nil
+	}
+	if username == "" || key == "" {
+		return nil, errors.New("username or key is empty")
+	}
+	return StaticCredentials(username, key), nil
+}
@@ -0,0 +1,532 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+)
+
+// The struct fields are mapped to the document by the vdb tag, the untagged fields are ignored:
+//
+//	type Book struct {
+//		Id     string                  `vdb:"id"`
+//		Vector []float32               `vdb:"vector"`
+//		Sparse []encoder.SparseVecItem `vdb:"sparse_vector"`
+//		Score  float32                 `vdb:"score"`
+//		Author string                  `vdb:"author,filter"`
+//		Page   uint64                  `vdb:"page,filter,omitempty"`
+//		Tags   []string                `vdb:"tags,filter"`
+//		Text   string                  `vdb:"text"`
+//	}
+//
+// The names id, vector, sparse_vector and score are the document attributes, the others are the fields.
+// The option filter marks the field as a filter index, see StructFilterIndexes,
+// and omitempty skips the zero value when upserting.
+const structTagName = "vdb"
+
+const (
+	structFieldId           = "id"
+	structFieldVector       = "vector"
+	structFieldSparseVector = "sparse_vector"
+	structFieldScore        = "score"
+)
+
+type structField struct {
+	name      string
+	index     int
+	filter    bool
+	omitEmpty bool
+}
+
+type structSchema struct {
+	fields []structField
+	// outputFields the field names to query, exclude the document attributes
+	outputFields []string
+	hasVector    bool
+}
+
+var structSchemas sync.Map
+
+// structSchemaOf returns the cached mapping of the struct type
+func structSchemaOf(t reflect.Type) (*structSchema, error) {
+	if s, ok := structSchemas.Load(t); ok {
+		return s.(*structSchema), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct", t)
+	}
+	schema := new(structSchema)
+	seen := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(structTagName)
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		field := structField{name: parts[0], index: i}
+		if field.name == "" {
+			return nil, fmt.Errorf("the field name of %v.%s is empty", t, sf.Name)
+		}
+		if seen[field.name] {
+			return nil, fmt.Errorf("the field %s of %v is duplicated", field.name, t)
+		}
+		seen[field.name] = true
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "filter":
+				field.filter = true
+			case "omitempty":
+				field.omitEmpty = true
+			default:
+				return nil, fmt.Errorf("unknown option %q of %v.%s", opt, t, sf.Name)
+			}
+		}
+		if err := checkStructFieldType(field.name, sf.Type); err != nil {
+			return nil, fmt.Errorf("%v.%s: %v", t, sf.Name, err)
+		}
+		switch field.name {
+		case structFieldVector:
+			schema.hasVector = true
+		case structFieldId, structFieldSparseVector, structFieldScore:
+		default:
+			schema.outputFields = append(schema.outputFields, field.name)
+		}
+		schema.fields = append(schema.fields, field)
+	}
+	s, _ := structSchemas.LoadOrStore(t, schema)
+	return s.(*structSchema), nil
+}
+
+func checkStructFieldType(name string, t reflect.Type) error {
+	var expect reflect.Type
+	switch name {
+	case structFieldId:
+		expect = reflect.TypeOf("")
+	case structFieldVector:
+		expect = reflect.TypeOf([]float32(nil))
+	case structFieldSparseVector:
+		expect = reflect.TypeOf([]encoder.SparseVecItem(nil))
+	case structFieldScore:
+		expect = reflect.TypeOf(float32(0))
+	}
+	if expect != nil {
+		if t != expect {
+			return fmt.Errorf("the type of %s must be %v, got %v", name, expect, t)
+		}
+		return nil
+	}
+	if t == reflect.TypeOf(Field{}) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Slice:
+		switch t.Elem().Kind() {
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported type %v of the field %s", t, name)
+}
+
+// structSliceElem returns the struct type of []T or []*T
+func structSliceElem(t reflect.Type) (reflect.Type, bool, error) {
+	if t.Kind() != reflect.Slice {
+		return nil, false, fmt.Errorf("%v is not a slice of struct", t)
+	}
+	elem := t.Elem()
+	isPtr := elem.Kind() == reflect.Ptr
+	if isPtr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, false, fmt.Errorf("%v is not a slice of struct", t)
+	}
+	return elem, isPtr, nil
+}
+
+// StructsToDocuments converts []T or []*T to the documents by the vdb tags
+func StructsToDocuments(structs interface{}) ([]Document, error) {
+	v := reflect.ValueOf(structs)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("the structs is nil")
+	}
+	elem, isPtr, err := structSliceElem(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	schema, err := structSchemaOf(elem)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Document, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if isPtr {
+			if item.IsNil() {
+				return nil, fmt.Errorf("the structs[%d] is nil", i)
+			}
+			item = item.Elem()
+		}
+		docs = append(docs, schema.toDocument(item))
+	}
+	return docs, nil
+}
+
+func (s *structSchema) toDocument(v reflect.Value) Document {
+	doc := Document{Fields: make(map[string]Field)}
+	for _, f := range s.fields {
+		fv := v.Field(f.index)
+		switch f.name {
+		case structFieldId:
+			doc.Id = fv.String()
+		case structFieldVector:
+			doc.Vector = fv.Interface().([]float32)
+		case structFieldSparseVector:
+			doc.SparseVector = fv.Interface().([]encoder.SparseVecItem)
+		case structFieldScore:
+		default:
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			if field, ok := fv.Interface().(Field); ok {
+				doc.Fields[f.name] = field
+			} else {
+				doc.Fields[f.name] = Field{Val: fv.Interface()}
+			}
+		}
+	}
+	return doc
+}
+
+// DocumentsToStructs fills the documents into dest, which is a pointer to []T or []*T
+func DocumentsToStructs(docs []Document, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("the dest must be a non-nil pointer to a slice of struct, got %T", dest)
+	}
+	slice := v.Elem()
+	elem, isPtr, err := structSliceElem(slice.Type())
+	if err != nil {
+		return err
+	}
+	schema, err := structSchemaOf(elem)
+	if err != nil {
+		return err
+	}
+	out := reflect.MakeSlice(slice.Type(), len(docs), len(docs))
+	for i := range docs {
+		item := reflect.New(elem)
+		if err = schema.fromDocument(&docs[i], item.Elem()); err != nil {
+			return fmt.Errorf("document %s: %v", docs[i].Id, err)
+		}
+		if isPtr {
+			out.Index(i).Set(item)
+		} else {
+			out.Index(i).Set(item.Elem())
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+func (s *structSchema) fromDocument(doc *Document, v reflect.Value) error {
+	for _, f := range s.fields {
+		fv := v.Field(f.index)
+		switch f.name {
+		case structFieldId:
+			fv.SetString(doc.Id)
+		case structFieldVector:
+			fv.Set(reflect.ValueOf(doc.Vector))
+		case structFieldSparseVector:
+			fv.Set(reflect.ValueOf(doc.SparseVector))
+		case structFieldScore:
+			fv.SetFloat(float64(doc.Score))
+		default:
+			field, ok := doc.Fields[f.name]
+			if !ok || field.Val == nil {
+				continue
+			}
+			if err := setStructField(fv, field); err != nil {
+				return fmt.Errorf("field %s: %v", f.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setStructField sets the field value to the struct field, the value must be of the kind of the struct field,
+// and in the range of it. The arrays are set to the slices element by element.
+func setStructField(fv reflect.Value, field Field) error {
+	if fv.Type() == reflect.TypeOf(Field{}) {
+		fv.Set(reflect.ValueOf(field))
+		return nil
+	}
+	if fv.Kind() != reflect.Slice {
+		return setStructValue(fv, field.Val)
+	}
+	v := reflect.ValueOf(field.Val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("cannot set %T to %v", field.Val, fv.Type())
+	}
+	out := reflect.MakeSlice(fv.Type(), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := setStructValue(out.Index(i), v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	fv.Set(out)
+	return nil
+}
+
+func setStructValue(fv reflect.Value, val interface{}) error {
+	mismatch := fmt.Errorf("cannot set %T to %v", val, fv.Type())
+	switch fv.Kind() {
+	case reflect.Interface:
+		// interface{} takes any value, the other interfaces only the values implementing them
+		v := reflect.ValueOf(val)
+		if !v.IsValid() || !v.Type().AssignableTo(fv.Type()) {
+			return mismatch
+		}
+		fv.Set(v)
+	case reflect.String:
+		s, ok := val.(string)
+		if !ok {
+			return mismatch
+		}
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := structInt(val)
+		if !ok || fv.OverflowInt(n) {
+			return mismatch
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := structUint(val)
+		if !ok || fv.OverflowUint(n) {
+			return mismatch
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, ok := structFloat(val)
+		if !ok || fv.OverflowFloat(n) {
+			return mismatch
+		}
+		fv.SetFloat(n)
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// structInt returns the integer value of the numbers, the fractions and the out of range values are not ok
+func structInt(val interface{}) (int64, bool) {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), v.Uint() <= math.MaxInt64
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return int64(f), f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+	}
+	if n, ok := val.(json.Number); ok {
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// structUint returns the unsigned integer value of the numbers, the negative ones are not ok
+func structUint(val interface{}) (uint64, bool) {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), v.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return uint64(f), f == math.Trunc(f) && f >= 0 && f < math.MaxUint64
+	}
+	if n, ok := val.(json.Number); ok {
+		u, err := strconv.ParseUint(string(n), 10, 64)
+		return u, err == nil
+	}
+	return 0, false
+}
+
+func structFloat(val interface{}) (float64, bool) {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	if n, ok := val.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// StructOutputFields returns the field names mapped by the struct T, v is a T, *T or []T
+func StructOutputFields(v interface{}) ([]string, error) {
+	schema, err := structSchemaOfValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), schema.outputFields...), nil
+}
+
+// StructFilterIndexes returns the filter indexes declared by the struct T, v is a T, *T or []T.
+// The id field is the primary key, and the fields with the filter option are the filter indexes:
+// the strings are String, the integers are Uint64 and the string slices are Array of String.
+func StructFilterIndexes(v interface{}) ([]FilterIndex, error) {
+	schema, err := structSchemaOfValue(v)
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	var indexes []FilterIndex
+	for _, f := range schema.fields {
+		if f.name == structFieldId {
+			indexes = append(indexes, FilterIndex{FieldName: f.name, FieldType: String, IndexType: PRIMARY})
+			continue
+		}
+		if !f.filter {
+			continue
+		}
+		index := FilterIndex{FieldName: f.name, IndexType: FILTER}
+		ft := t.Field(f.index).Type
+		switch ft.Kind() {
+		case reflect.String:
+			index.FieldType = String
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			index.FieldType = Uint64
+		case reflect.Slice:
+			if ft.Elem().Kind() != reflect.String {
+				return nil, fmt.Errorf("the filter field %s must be an array of string, got %v", f.name, ft)
+			}
+			index.FieldType, index.ElemType = Array, String
+		default:
+			return nil, fmt.Errorf("the type %v of the filter field %s is unsupported", ft, f.name)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+func structSchemaOfValue(v interface{}) (*structSchema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("the struct is nil")
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return structSchemaOf(t)
+}
+
+// UpsertStructs upserts []T or []*T, which are mapped to the documents by the vdb tags
+func (c *Collection) UpsertStructs(ctx context.Context, structs interface{}, params ...*UpsertDocumentParams) (*UpsertDocumentResult, error) {
+	docs, err := StructsToDocuments(structs)
+	if err != nil {
+		return nil, err
+	}
+	return c.Upsert(ctx, docs, params...)
+}
+
+// QueryInto queries the documents into dest, which is a pointer to []T or []*T.
+// The OutputFields are derived from T if they are not set, and the vector is retrieved if T has the vector field.
+func (c *Collection) QueryInto(ctx context.Context, documentIds []string, dest interface{}, params ...*QueryDocumentParams) (*QueryDocumentResult, error) {
+	schema, err := structSchemaOfValue(dest)
+	if err != nil {
+		return nil, err
+	}
+	param := new(QueryDocumentParams)
+	if len(params) != 0 && params[0] != nil {
+		*param = *params[0]
+	}
+	if len(param.OutputFields) == 0 {
+		param.OutputFields = schema.outputFields
+	}
+	param.RetrieveVector = param.RetrieveVector || schema.hasVector
+	result, err := c.Query(ctx, documentIds, param)
+	if err != nil {
+		return nil, err
+	}
+	return result, DocumentsToStructs(result.Documents, dest)
+}
+
+// SearchInto searches the documents into dest, which is a pointer to [][]T or [][]*T, one slice for each vector.
+// The OutputFields are derived from T if they are not set, and the vector is retrieved if T has the vector field.
+func (c *Collection) SearchInto(ctx context.Context, vectors [][]float32, dest interface{}, params ...*SearchDocumentParams) (*SearchDocumentResult, error) {
+	v := reflect.ValueOf(dest)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("the dest must be a non-nil pointer to [][]T, got %T", dest)
+	}
+	schema, err := structSchemaOfValue(dest)
+	if err != nil {
+		return nil, err
+	}
+	param := new(SearchDocumentParams)
+	if len(params) != 0 && params[0] != nil {
+		*param = *params[0]
+	}
+	if len(param.OutputFields) == 0 {
+		param.OutputFields = schema.outputFields
+	}
+	param.RetrieveVector = param.RetrieveVector || schema.hasVector
+	result, err := c.Search(ctx, vectors, param)
+	if err != nil {
+		return nil, err
+	}
+	outer := v.Elem()
+	out := reflect.MakeSlice(outer.Type(), len(result.Documents), len(result.Documents))
+	for i, docs := range result.Documents {
+		if err = DocumentsToStructs(docs, out.Index(i).Addr().Interface()); err != nil {
+			return result, err
+		}
+	}
+	outer.Set(out)
+	return result, nil
+}
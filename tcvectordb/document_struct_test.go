@@ -0,0 +1,169 @@
+package tcvectordb_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/tcvectordbtest"
+)
+
+type testBook struct {
+	Id     string                  `vdb:"id"`
+	Vector []float32               `vdb:"vector"`
+	Sparse []encoder.SparseVecItem `vdb:"sparse_vector"`
+	Score  float32                 `vdb:"score"`
+	Author string                  `vdb:"author,filter"`
+	Page   int                     `vdb:"page,filter,omitempty"`
+	Tags   []string                `vdb:"tags,filter"`
+	Note   string
+}
+
+func TestStructMapping(t *testing.T) {
+	srv := tcvectordbtest.NewRpcServer()
+	defer srv.Close()
+	cli, err := srv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+
+	filterIndexes, err := tcvectordb.StructFilterIndexes([]testBook{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filterIndexes) != 4 || filterIndexes[0].IndexType != tcvectordb.PRIMARY || filterIndexes[3].FieldType != tcvectordb.Array {
+		t.Fatalf("unexpected filter indexes %+v", filterIndexes)
+	}
+	db, err := cli.CreateDatabase(ctx, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll, err := db.CreateCollection(ctx, "coll", 1, 1, "", tcvectordb.Indexes{
+		VectorIndex: []tcvectordb.VectorIndex{{
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   2,
+			MetricType:  tcvectordb.L2,
+		}},
+		SparseVectorIndex: []tcvectordb.SparseVectorIndex{{
+			FieldName: "sparse_vector", FieldType: tcvectordb.SparseVector,
+			IndexType: tcvectordb.SPARSE_INVERTED, MetricType: tcvectordb.IP,
+		}},
+		FilterIndex: filterIndexes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	books := []*testBook{
+		{Id: "0001", Vector: []float32{1, 0}, Sparse: []encoder.SparseVecItem{{TermId: 1, Score: 0.5}},
+			Author: "jerry", Page: 10, Tags: []string{"a"}, Note: "not mapped"},
+		{Id: "0002", Vector: []float32{0, 1}, Author: "tom", Tags: []string{"b"}},
+	}
+	if _, err = coll.UpsertStructs(ctx, books); err != nil {
+		t.Fatal(err)
+	}
+
+	var queried []testBook
+	if _, err = coll.QueryInto(ctx, []string{"0001", "0002"}, &queried); err != nil {
+		t.Fatal(err)
+	}
+	if len(queried) != 2 || queried[0].Author != "jerry" || queried[0].Page != 10 || queried[0].Tags[0] != "a" ||
+		len(queried[0].Vector) != 2 || len(queried[0].Sparse) != 1 || queried[0].Note != "" || queried[1].Page != 0 {
+		t.Fatalf("unexpected queried books %+v", queried)
+	}
+
+	var searched [][]*testBook
+	if _, err = coll.SearchInto(ctx, [][]float32{{0, 1}, {1, 0}}, &searched, &tcvectordb.SearchDocumentParams{
+		Filter: tcvectordb.NewFilter(`author in ("tom", "jerry")`), Limit: 1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(searched) != 2 || searched[0][0].Id != "0002" || searched[0][0].Author != "tom" || searched[1][0].Id != "0001" {
+		t.Fatalf("unexpected searched books %+v", searched)
+	}
+
+	var invalid []struct {
+		Vector string `vdb:"vector"`
+	}
+	if _, err = coll.QueryInto(ctx, nil, &invalid); err == nil {
+		t.Fatal("expect the invalid vector type is rejected")
+	}
+}
+
+func TestDocumentsToStructsInterface(t *testing.T) {
+	docs := []tcvectordb.Document{{Id: "0001", Fields: map[string]tcvectordb.Field{
+		"author": {Val: "tom"}, "page": {Val: uint64(10)},
+	}}}
+	var empty []struct {
+		Author interface{} `vdb:"author"`
+		Page   interface{} `vdb:"page"`
+	}
+	if err := tcvectordb.DocumentsToStructs(docs, &empty); err != nil || empty[0].Author != "tom" || empty[0].Page != uint64(10) {
+		t.Fatalf("unexpected structs %+v, %v", empty, err)
+	}
+	var stringer []struct {
+		Author fmt.Stringer `vdb:"author"`
+	}
+	if err := tcvectordb.DocumentsToStructs(docs, &stringer); err == nil {
+		t.Fatal("expect the value not implementing the interface is rejected")
+	}
+}
+
+func TestDocumentsToStructsConversion(t *testing.T) {
+	type numbers struct {
+		Int8    int8     `vdb:"int8"`
+		Int     int      `vdb:"int"`
+		Uint16  uint16   `vdb:"uint16"`
+		Float32 float32  `vdb:"float32"`
+		Name    string   `vdb:"name"`
+		Ids     []uint64 `vdb:"ids"`
+		Offsets []int64  `vdb:"offsets"`
+	}
+	convert := func(fields map[string]tcvectordb.Field) (numbers, error) {
+		var out []numbers
+		err := tcvectordb.DocumentsToStructs([]tcvectordb.Document{{Id: "0001", Fields: fields}}, &out)
+		if err != nil {
+			return numbers{}, err
+		}
+		return out[0], nil
+	}
+
+	got, err := convert(map[string]tcvectordb.Field{
+		"int8":    {Val: json.Number("-5")},
+		"int":     {Val: int64(-7)},
+		"uint16":  {Val: float64(65535)},
+		"float32": {Val: json.Number("0.5")},
+		"name":    {Val: "tom"},
+		"ids":     {Val: []interface{}{uint64(1), json.Number("2")}},
+		"offsets": {Val: []interface{}{int64(-1), float64(127)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Int8 != -5 || got.Int != -7 || got.Uint16 != 65535 || got.Float32 != 0.5 || got.Name != "tom" ||
+		len(got.Ids) != 2 || got.Ids[1] != 2 || got.Offsets[0] != -1 || got.Offsets[1] != 127 {
+		t.Fatalf("unexpected struct %+v", got)
+	}
+
+	for name, field := range map[string]tcvectordb.Field{
+		"int8":    {Val: int64(128)},
+		"int":     {Val: "10"},
+		"uint16":  {Val: int64(-1)},
+		"float32": {Val: float64(1e39)},
+		"name":    {Val: uint64(10)},
+		"ids":     {Val: []interface{}{uint64(1), int64(-2)}},
+		"offsets": {Val: []interface{}{int64(1), "2"}},
+	} {
+		if _, err = convert(map[string]tcvectordb.Field{name: field}); err == nil || !strings.Contains(err.Error(), "cannot set") {
+			t.Fatalf("%s: expect %v is rejected, got %v", name, field.Val, err)
+		}
+	}
+	if _, err = convert(map[string]tcvectordb.Field{"ids": {Val: uint64(1)}}); err == nil {
+		t.Fatal("expect the scalar is rejected by the slice field")
+	}
+}
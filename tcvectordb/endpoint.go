@@ -0,0 +1,184 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type LoadBalancePolicy string
+
+const (
+	// RoundRobin spread the read requests across the healthy endpoints in turn
+	RoundRobin LoadBalancePolicy = "roundRobin"
+	// LeastInFlight send the read requests to the healthy endpoint with the fewest in-flight requests
+	LeastInFlight LoadBalancePolicy = "leastInFlight"
+)
+
+// splitEndpoints split the comma separated url into endpoints
+func splitEndpoints(url string) []string {
+	var endpoints []string
+	for _, v := range strings.Split(url, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			endpoints = append(endpoints, v)
+		}
+	}
+	return endpoints
+}
+
+type endpoint struct {
+	url      string
+	inFlight int64
+	// ejectedUntil the unix nano time until which the endpoint is unhealthy
+	ejectedUntil int64
+	// breaker is nil if the circuit breaker is disabled
+	breaker *circuitBreaker
+}
+
+func (e *endpoint) healthy(now int64) bool {
+	return atomic.LoadInt64(&e.ejectedUntil) <= now && !e.breaker.open(time.Unix(0, now))
+}
+
+// endpointSelector choose the endpoint for each request. Reads are balanced by the policy,
+// writes are pinned to the first endpoint, which is the primary, even while it is ejected.
+type endpointSelector struct {
+	endpoints     []*endpoint
+	policy        LoadBalancePolicy
+	ejectDuration time.Duration
+	next          uint64
+}
+
+func newEndpointSelector(urls []string, policy LoadBalancePolicy, ejectDuration time.Duration,
+	breaker *CircuitBreakerOption) *endpointSelector {
+	s := &endpointSelector{policy: policy, ejectDuration: ejectDuration}
+	for _, url := range urls {
+		s.endpoints = append(s.endpoints, &endpoint{url: url, breaker: newCircuitBreaker(breaker, url)})
+	}
+	return s
+}
+
+// pick choose an endpoint not in tried. It returns nil if all endpoints are tried.
+// Writes always get the primary. For reads, if all the untried endpoints are ejected,
+// the one which recovers soonest is used.
+func (s *endpointSelector) pick(write bool, tried map[*endpoint]bool) *endpoint {
+	if write {
+		if tried[s.endpoints[0]] {
+			return nil
+		}
+		return s.endpoints[0]
+	}
+	now := time.Now().UnixNano()
+	var candidates []*endpoint
+	var fallback *endpoint
+	for _, e := range s.endpoints {
+		if tried[e] {
+			continue
+		}
+		if e.healthy(now) {
+			candidates = append(candidates, e)
+		} else if fallback == nil || atomic.LoadInt64(&e.ejectedUntil) < atomic.LoadInt64(&fallback.ejectedUntil) {
+			fallback = e
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	if s.policy == LeastInFlight {
+		best := candidates[0]
+		for _, e := range candidates[1:] {
+			if atomic.LoadInt64(&e.inFlight) < atomic.LoadInt64(&best.inFlight) {
+				best = e
+			}
+		}
+		return best
+	}
+	n := atomic.AddUint64(&s.next, 1)
+	return candidates[(n-1)%uint64(len(candidates))]
+}
+
+func (s *endpointSelector) eject(e *endpoint) {
+	atomic.StoreInt64(&e.ejectedUntil, time.Now().Add(s.ejectDuration).UnixNano())
+}
+
+func (s *endpointSelector) recover(e *endpoint) {
+	if atomic.LoadInt64(&e.ejectedUntil) != 0 {
+		atomic.StoreInt64(&e.ejectedUntil, 0)
+	}
+}
+
+// do run fn on the chosen endpoint. Read requests fail over to the other endpoints on connection errors
+// or open circuit breakers, write requests only use the primary and return its error.
+func (s *endpointSelector) do(path string, fn func(e *endpoint) error) error {
+	write := !isReadPath(path)
+	tried := make(map[*endpoint]bool)
+	for {
+		e := s.pick(write, tried)
+		tried[e] = true
+		if !e.breaker.allow() {
+			if write || len(tried) == len(s.endpoints) {
+				return ErrCircuitOpen
+			}
+			continue
+		}
+		atomic.AddInt64(&e.inFlight, 1)
+		err := fn(e)
+		atomic.AddInt64(&e.inFlight, -1)
+		e.breaker.record(err)
+		if !isConnectionError(err) {
+			if err == nil {
+				s.recover(e)
+			}
+			return err
+		}
+		s.eject(e)
+		if write || len(tried) == len(s.endpoints) {
+			return err
+		}
+	}
+}
+
+// isConnectionError reports whether err means the endpoint is unreachable
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		return s.Code() == codes.Unavailable
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
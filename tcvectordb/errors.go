@@ -0,0 +1,76 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// ErrDatabaseNotFound the database does not exist
+	ErrDatabaseNotFound = errors.New("database not found")
+	// ErrCollectionNotFound the collection or ai collectionView does not exist
+	ErrCollectionNotFound = errors.New("collection not found")
+)
+
+// ServerError is returned when the vectordb server responds with a failure, on both the http and
+// grpc transports. Use errors.As to get it, or errors.Is with the sentinel errors such as ErrDatabaseNotFound.
+type ServerError struct {
+	// StatusCode: the http status code, 0 on the grpc transport
+	StatusCode int
+	// Code: the code in the response body, 0 means the http status is not 2xx
+	Code int32
+	// Message: the msg in the response body, or the whole body if it is not a vectordb response
+	Message string
+	// Path: the http path or the grpc method of the request, eg: /document/search
+	Path string
+}
+
+func (e *ServerError) Error() string {
+	if e.Code == 0 && e.StatusCode/100 != 2 {
+		return fmt.Sprintf("response code is %d, %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("code: %d, message: %s", e.Code, e.Message)
+}
+
+// Is maps the server error to the sentinel errors by the server codes. Without a known code, as on
+// the servers before the codes were added, it falls back to the messages naming the missing resource,
+// eg: collection xxx not exist.
+func (e *ServerError) Is(target error) bool {
+	switch e.Code {
+	case ERR_UNDEFINED_DATABASE:
+		return target == ErrDatabaseNotFound
+	case ERR_UNDEFINED_COLLECTION:
+		return target == ErrCollectionNotFound
+	}
+	pattern, ok := notFoundMessages[target]
+	return ok && pattern.MatchString(e.Message)
+}
+
+var notFoundMessages = map[error]*regexp.Regexp{
+	ErrDatabaseNotFound:   notFoundMessage("database"),
+	ErrCollectionNotFound: notFoundMessage("collection|collectionView"),
+}
+
+// notFoundMessage matches "<resource> [name] not exist" and "can not find <resource>"
+func notFoundMessage(resource string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(` + resource + `)\s+(\S+\s+)?(does\s+)?not\s+exist|can\s*not\s+find\s+(` + resource + `)\b`)
+}
@@ -0,0 +1,300 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ExportFormat the format of the first line written by ExportCollection
+const ExportFormat = "tcvectordb-export/v1"
+
+// ExportHeader is the first line of the export, {"header": {...}}, which describes the collection
+type ExportHeader struct {
+	Format        string              `json:"format"`
+	Database      string              `json:"database"`
+	Collection    string              `json:"collection"`
+	Description   string              `json:"description,omitempty"`
+	ShardNum      uint32              `json:"shardNum"`
+	ReplicasNum   uint32              `json:"replicasNum"`
+	DocumentCount int64               `json:"documentCount"`
+	VectorIndex   []ExportVectorIndex `json:"vectorIndex,omitempty"`
+	SparseIndex   []ExportSparseIndex `json:"sparseVectorIndex,omitempty"`
+	FilterIndex   []ExportFilterIndex `json:"filterIndex,omitempty"`
+	Embedding     *Embedding          `json:"embedding,omitempty"`
+	TtlConfig     *TtlConfig          `json:"ttlConfig,omitempty"`
+}
+
+// ExportVectorIndex the vector index with the params of all the index types
+type ExportVectorIndex struct {
+	FieldName      string     `json:"fieldName"`
+	FieldType      FieldType  `json:"fieldType"`
+	IndexType      IndexType  `json:"indexType"`
+	Dimension      uint32     `json:"dimension"`
+	MetricType     MetricType `json:"metricType"`
+	M              uint32     `json:"m,omitempty"`
+	EfConstruction uint32     `json:"efConstruction,omitempty"`
+	NList          uint32     `json:"nlist,omitempty"`
+}
+
+type ExportSparseIndex struct {
+	FieldName  string     `json:"fieldName"`
+	FieldType  FieldType  `json:"fieldType"`
+	IndexType  IndexType  `json:"indexType"`
+	MetricType MetricType `json:"metricType"`
+}
+
+type ExportFilterIndex struct {
+	FieldName string    `json:"fieldName"`
+	FieldType FieldType `json:"fieldType"`
+	ElemType  FieldType `json:"elemType,omitempty"`
+	IndexType IndexType `json:"indexType"`
+}
+
+type exportHeaderLine struct {
+	Header *ExportHeader `json:"header"`
+}
+
+type ExportOption struct {
+	// Filter: export the documents matching the filter, default all
+	Filter *Filter
+	// PageSize: the limit of each query, default 100
+	PageSize int64
+	// OnProgress: called with the exported document count after each page
+	OnProgress func(exported int64)
+}
+
+// ExportResult the result of ExportCollection
+type ExportResult struct {
+	Header    *ExportHeader
+	Documents int64
+}
+
+// ExportCollection writes the collection as JSONL: the header with the schema from DescribeCollection,
+// then one document per line with the id, vector, sparse_vector and all the fields.
+// The documents are read page by page with Query, the writes during the export may be missed or repeated.
+// The document lines could be imported by ImportJSONL, and the whole export is restored by RestoreCollection.
+func ExportCollection(ctx context.Context, coll *Collection, w io.Writer, option *ExportOption) (*ExportResult, error) {
+	opt := ExportOption{}
+	if option != nil {
+		opt = *option
+	}
+	if opt.PageSize <= 0 {
+		opt.PageSize = 100
+	}
+	described, err := coll.describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExportResult{Header: newExportHeader(described)}
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(exportHeaderLine{Header: result.Header}); err != nil {
+		return nil, err
+	}
+
+	for offset := int64(0); ; offset += opt.PageSize {
+		res, err := coll.Query(ctx, nil, &QueryDocumentParams{
+			Filter:         opt.Filter,
+			RetrieveVector: true,
+			Offset:         offset,
+			Limit:          opt.PageSize,
+		})
+		if err != nil {
+			buf.Flush()
+			return result, fmt.Errorf("query at offset %d failed: %w", offset, err)
+		}
+		for _, doc := range res.Documents {
+			if err = encoder.Encode(exportDocument(doc)); err != nil {
+				return result, err
+			}
+			result.Documents++
+		}
+		if opt.OnProgress != nil {
+			opt.OnProgress(result.Documents)
+		}
+		if int64(len(res.Documents)) < opt.PageSize {
+			break
+		}
+	}
+	return result, buf.Flush()
+}
+
+func newExportHeader(coll *Collection) *ExportHeader {
+	header := &ExportHeader{
+		Format:        ExportFormat,
+		Database:      coll.DatabaseName,
+		Collection:    coll.CollectionName,
+		Description:   coll.Description,
+		ShardNum:      coll.ShardNum,
+		ReplicasNum:   coll.ReplicasNum,
+		DocumentCount: coll.DocumentCount,
+		TtlConfig:     coll.TtlConfig,
+	}
+	for _, index := range coll.Indexes.SparseVectorIndex {
+		header.SparseIndex = append(header.SparseIndex, ExportSparseIndex(index))
+	}
+	for _, index := range coll.Indexes.FilterIndex {
+		header.FilterIndex = append(header.FilterIndex, ExportFilterIndex(index))
+	}
+	for _, index := range coll.Indexes.VectorIndex {
+		vector := ExportVectorIndex{
+			FieldName:  index.FieldName,
+			FieldType:  index.FieldType,
+			IndexType:  index.IndexType,
+			Dimension:  index.Dimension,
+			MetricType: index.MetricType,
+		}
+		switch params := index.Params.(type) {
+		case *HNSWParam:
+			vector.M, vector.EfConstruction = params.M, params.EfConstruction
+		case *IVFFLATParams:
+			vector.NList = params.NList
+		case *IVFSQParams:
+			vector.NList = params.NList
+		case *IVFPQParams:
+			vector.M, vector.NList = params.M, params.NList
+		}
+		header.VectorIndex = append(header.VectorIndex, vector)
+	}
+	if coll.Embedding.Model != "" {
+		embedding := coll.Embedding
+		header.Embedding = &embedding
+	}
+	return header
+}
+
+// Indexes returns the indexes to create the collection
+func (h *ExportHeader) Indexes() Indexes {
+	indexes := Indexes{}
+	for _, index := range h.SparseIndex {
+		indexes.SparseVectorIndex = append(indexes.SparseVectorIndex, SparseVectorIndex(index))
+	}
+	for _, index := range h.FilterIndex {
+		indexes.FilterIndex = append(indexes.FilterIndex, FilterIndex(index))
+	}
+	for _, index := range h.VectorIndex {
+		vector := VectorIndex{
+			FilterIndex: FilterIndex{FieldName: index.FieldName, FieldType: index.FieldType, IndexType: index.IndexType},
+			Dimension:   index.Dimension,
+			MetricType:  index.MetricType,
+		}
+		switch index.IndexType {
+		case HNSW:
+			vector.Params = &HNSWParam{M: index.M, EfConstruction: index.EfConstruction}
+		case IVF_FLAT:
+			vector.Params = &IVFFLATParams{NList: index.NList}
+		case IVF_SQ8:
+			vector.Params = &IVFSQParams{NList: index.NList}
+		case IVF_PQ:
+			vector.Params = &IVFPQParams{M: index.M, NList: index.NList}
+		}
+		indexes.VectorIndex = append(indexes.VectorIndex, vector)
+	}
+	return indexes
+}
+
+func exportDocument(doc Document) map[string]interface{} {
+	record := make(map[string]interface{}, len(doc.Fields)+3)
+	for k, v := range doc.Fields {
+		record[k] = v.Val
+	}
+	record["id"] = doc.Id
+	if len(doc.Vector) != 0 {
+		record["vector"] = doc.Vector
+	}
+	if len(doc.SparseVector) != 0 {
+		sparse := make([][]interface{}, 0, len(doc.SparseVector))
+		for _, item := range doc.SparseVector {
+			sparse = append(sparse, []interface{}{item.TermId, item.Score})
+		}
+		record["sparse_vector"] = sparse
+	}
+	return record
+}
+
+type RestoreOption struct {
+	// Collection: the name of the restored collection, default the exported name
+	Collection string
+	// DropExisting: drop the collection before creating it
+	DropExisting bool
+	// SkipCreate: upsert into the existing collection, the schema in the header is not used
+	SkipCreate bool
+	// ImportOption: the options to upsert the documents, the Columns and Validate are ignored
+	ImportOption
+}
+
+// ReadExportHeader reads the header of the export
+func ReadExportHeader(r *bufio.Reader) (*ExportHeader, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return nil, fmt.Errorf("read the export header failed: %w", err)
+	}
+	var header exportHeaderLine
+	if err = json.Unmarshal(line, &header); err != nil || header.Header == nil {
+		return nil, errors.New("the export header is missing")
+	}
+	if header.Header.Format != ExportFormat {
+		return nil, fmt.Errorf("unsupported export format %q", header.Header.Format)
+	}
+	return header.Header, nil
+}
+
+// RestoreCollection recreates the collection written by ExportCollection in the database,
+// and upserts the documents.
+func RestoreCollection(ctx context.Context, db *Database, r io.Reader, option *RestoreOption) (*ImportResult, error) {
+	opt := RestoreOption{}
+	if option != nil {
+		opt = *option
+	}
+	reader := bufio.NewReader(r)
+	header, err := ReadExportHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	name := opt.Collection
+	if name == "" {
+		name = header.Collection
+	}
+	var coll *Collection
+	if opt.SkipCreate {
+		coll = db.Collection(name)
+	} else {
+		if opt.DropExisting {
+			if _, err = db.DropCollection(ctx, name); err != nil {
+				return nil, err
+			}
+		}
+		params := &CreateCollectionParams{Embedding: header.Embedding, TtlConfig: header.TtlConfig}
+		coll, err = db.CreateCollection(ctx, name, header.ShardNum, header.ReplicasNum, header.Description,
+			header.Indexes(), params)
+		if err != nil {
+			return nil, err
+		}
+	}
+	importOption := opt.ImportOption
+	importOption.Columns, importOption.Validate = nil, false
+	return importRecords(ctx, coll, &importOption, jsonlRecords(reader))
+}
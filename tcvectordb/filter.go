@@ -23,8 +23,13 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/filter"
 )
 
+// Filter the filter condition of the requests. And, Or, AndNot and OrNot append to the condition of
+// the filter itself and return it, so a filter shared by several requests is changed for all of them.
+// Compose the conditions with the immutable filter.Expr and NewFilterExpr to keep each filter unchanged.
 type Filter struct {
 	cond string
 	sync.RWMutex
@@ -36,7 +41,17 @@ func NewFilter(cond string) *Filter {
 	return f
 }
 
+// NewFilterExpr returns the filter of the typed expression, or the error of building it
+func NewFilterExpr(expr filter.Expr) (*Filter, error) {
+	cond, err := expr.Build()
+	if err != nil {
+		return nil, err
+	}
+	return NewFilter(cond), nil
+}
+
 // And `and` condition, eg: And(`key1 = "string value"`).And("key2=0")
+// It changes f and returns it.
 func (f *Filter) And(cond string) *Filter {
 	f.Lock()
 	defer f.Unlock()
@@ -49,6 +64,7 @@ func (f *Filter) And(cond string) *Filter {
 }
 
 // Or `or` condition, eg: Or(`key1 = "string value"`).Or("key2=0")
+// It changes f and returns it.
 func (f *Filter) Or(cond string) *Filter {
 	f.Lock()
 	defer f.Unlock()
@@ -61,6 +77,7 @@ func (f *Filter) Or(cond string) *Filter {
 }
 
 // AndNot `and not` condition, eg: AndNot(`key1 = "string value"`).AndNot("key2=0")
+// It changes f and returns it.
 func (f *Filter) AndNot(cond string) *Filter {
 	f.Lock()
 	defer f.Unlock()
@@ -73,6 +90,7 @@ func (f *Filter) AndNot(cond string) *Filter {
 }
 
 // OrNot `or not` condition, eg: OrNot(`key1 = "string value"`).OrNot("key2=0")
+// It changes f and returns it.
 func (f *Filter) OrNot(cond string) *Filter {
 	f.Lock()
 	defer f.Unlock()
@@ -100,7 +118,7 @@ func In(key string, list interface{}) string {
 		b.WriteString(",")
 		v := values.Index(i)
 		if v.Kind() == reflect.String {
-			b.WriteString(fmt.Sprintf(`"%v"`, v.Interface()))
+			b.WriteString(filter.Quote(v.String()))
 		} else {
 			b.WriteString(fmt.Sprintf(`%v`, v.Interface()))
 		}
@@ -127,7 +145,7 @@ func NotIn(key string, list interface{}) string {
 		b.WriteString(",")
 		v := values.Index(i)
 		if v.Kind() == reflect.String {
-			b.WriteString(fmt.Sprintf(`"%v"`, v.Interface()))
+			b.WriteString(filter.Quote(v.String()))
 		} else {
 			b.WriteString(fmt.Sprintf(`%v`, v.Interface()))
 		}
@@ -152,7 +170,7 @@ func Include(key string, list interface{}) string {
 		b.WriteString(",")
 		v := values.Index(i)
 		if v.Kind() == reflect.String {
-			b.WriteString(fmt.Sprintf(`"%v"`, v.Interface()))
+			b.WriteString(filter.Quote(v.String()))
 		} else {
 			b.WriteString(fmt.Sprintf(`%v`, v.Interface()))
 		}
@@ -177,7 +195,7 @@ func Exclude(key string, list interface{}) string {
 		b.WriteString(",")
 		v := values.Index(i)
 		if v.Kind() == reflect.String {
-			b.WriteString(fmt.Sprintf(`"%v"`, v.Interface()))
+			b.WriteString(filter.Quote(v.String()))
 		} else {
 			b.WriteString(fmt.Sprintf(`%v`, v.Interface()))
 		}
@@ -202,7 +220,7 @@ func IncludeAll(key string, list interface{}) string {
 		b.WriteString(",")
 		v := values.Index(i)
 		if v.Kind() == reflect.String {
-			b.WriteString(fmt.Sprintf(`"%v"`, v.Interface()))
+			b.WriteString(filter.Quote(v.String()))
 		} else {
 			b.WriteString(fmt.Sprintf(`%v`, v.Interface()))
 		}
@@ -213,6 +231,33 @@ func IncludeAll(key string, list interface{}) string {
 	return ""
 }
 
+// Expr parses the condition of the filter, the error is a *filter.SyntaxError with the position
+func (f *Filter) Expr() (filter.Expr, error) {
+	return filter.Parse(f.Cond())
+}
+
+// Match parses the filter and evaluates it against the id and the fields of the document locally,
+// the nil filter matches all. Parse it once with Expr and use MatchDocument to evaluate many documents.
+func (f *Filter) Match(doc Document) (bool, error) {
+	expr, err := f.Expr()
+	if err != nil {
+		return false, err
+	}
+	return MatchDocument(expr, doc), nil
+}
+
+// MatchDocument evaluates the expression against the id and the fields of the document
+func MatchDocument(expr filter.Expr, doc Document) bool {
+	fields := make(map[string]interface{}, len(doc.Fields)+1)
+	for k, v := range doc.Fields {
+		fields[k] = v.Val
+	}
+	if doc.Id != "" {
+		fields["id"] = doc.Id
+	}
+	return expr.Eval(fields)
+}
+
 func (f *Filter) Cond() string {
 	if f == nil {
 		return ""
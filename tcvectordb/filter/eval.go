@@ -0,0 +1,192 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package filter
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Eval evaluates the expression against the fields of a document with the semantics of the server:
+// a condition on a missing field never matches whatever the operator is, a string never equals a number,
+// include, exclude and include all match the array fields only. The empty expression matches all,
+// the invalid expression matches none.
+func (e Expr) Eval(fields map[string]interface{}) bool {
+	if e.err != nil {
+		return false
+	}
+	switch e.kind {
+	case kindAnd:
+		for _, sub := range e.exprs {
+			if !sub.Eval(fields) {
+				return false
+			}
+		}
+		return true
+	case kindOr:
+		for _, sub := range e.exprs {
+			if sub.Eval(fields) {
+				return true
+			}
+		}
+		return false
+	case kindNot:
+		return !e.exprs[0].Eval(fields)
+	case kindCmp:
+		return e.evalCmp(fields)
+	}
+	return true
+}
+
+// Fields returns the field names used by the expression in order, which may be repeated
+func (e Expr) Fields() []string {
+	var names []string
+	e.walk(func(cmp Expr) { names = append(names, cmp.field) })
+	return names
+}
+
+// Condition a comparison of the expression, Values are strings or json.Numbers
+type Condition struct {
+	Field string
+	// Op: one of = != > >= < <= in, not in, include, exclude, include all
+	Op     string
+	Values []interface{}
+}
+
+// Conditions returns the comparisons of the expression in order
+func (e Expr) Conditions() []Condition {
+	var conds []Condition
+	e.walk(func(cmp Expr) {
+		conds = append(conds, Condition{Field: cmp.field, Op: cmp.op, Values: append([]interface{}(nil), cmp.values...)})
+	})
+	return conds
+}
+
+func (e Expr) walk(fn func(cmp Expr)) {
+	if e.kind == kindCmp {
+		fn(e)
+		return
+	}
+	for _, sub := range e.exprs {
+		sub.walk(fn)
+	}
+}
+
+func (e Expr) evalCmp(fields map[string]interface{}) bool {
+	v, ok := fields[e.field]
+	if !ok || v == nil {
+		return false
+	}
+	switch e.op {
+	case "in", "not in":
+		found := false
+		for _, want := range e.values {
+			if c, ok := compareValue(v, want); ok && c == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (e.op == "in")
+	case "include", "exclude", "include all":
+		arr := reflect.ValueOf(v)
+		if arr.Kind() != reflect.Slice && arr.Kind() != reflect.Array {
+			return false
+		}
+		matched := 0
+		for _, want := range e.values {
+			for i := 0; i < arr.Len(); i++ {
+				if c, ok := compareValue(arr.Index(i).Interface(), want); ok && c == 0 {
+					matched++
+					break
+				}
+			}
+		}
+		switch e.op {
+		case "include":
+			return matched > 0
+		case "exclude":
+			return matched == 0
+		}
+		return matched == len(e.values)
+	}
+	c, ok := compareValue(v, e.values[0])
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// compareValue compares the field value with the literal, ok is false if one is a string and the other is a number
+func compareValue(v, literal interface{}) (int, bool) {
+	if s, isString := literal.(string); isString {
+		if _, isNumber := v.(json.Number); isNumber {
+			return 0, false
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.String {
+			return 0, false
+		}
+		return strings.Compare(rv.String(), s), true
+	}
+	a, ok := ToNumber(v)
+	if !ok {
+		return 0, false
+	}
+	b, _ := ToNumber(literal)
+	return a.Cmp(b), true
+}
+
+// toNumber converts json.Number and the integer and float values to big.Float, so they compare exactly
+func ToNumber(v interface{}) (*big.Float, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, _, err := big.ParseFloat(string(n), 10, 128, big.ToNearestEven)
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetPrec(128).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Float).SetPrec(128).SetUint64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) {
+			return nil, false
+		}
+		return new(big.Float).SetPrec(128).SetFloat64(f), true
+	}
+	return nil, false
+}
@@ -0,0 +1,318 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package filter builds the filter conditions of the documents with the typed expressions,
+// the values are escaped when rendered, so it is safe to build the filters from the user input:
+//
+//	expr := filter.And(filter.Eq("author", name), filter.Between("page", 10, 20), filter.Include("tags", []string{"a"}))
+//	f, err := tcvectordb.NewFilterExpr(expr)
+//
+// The expressions are immutable, And and Or return new expressions. Parse parses the filter conditions
+// to the expressions, and Eval evaluates them against the fields of a document locally.
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type kind int
+
+const (
+	kindEmpty kind = iota
+	kindCmp
+	kindAnd
+	kindOr
+	kindNot
+)
+
+// Expr the filter expression, the zero value is the empty expression which matches all the documents
+type Expr struct {
+	kind  kind
+	field string
+	// op: one of = != > >= < <= in, not in, include, exclude, include all
+	op string
+	// values: string or json.Number
+	values []interface{}
+	exprs  []Expr
+	err    error
+}
+
+// Eq `field = value`, value is a string or a number
+func Eq(field string, value interface{}) Expr { return compare(field, "=", value) }
+
+// Ne `field != value`
+func Ne(field string, value interface{}) Expr { return compare(field, "!=", value) }
+
+// Gt `field > value`
+func Gt(field string, value interface{}) Expr { return compare(field, ">", value) }
+
+// Gte `field >= value`
+func Gte(field string, value interface{}) Expr { return compare(field, ">=", value) }
+
+// Lt `field < value`
+func Lt(field string, value interface{}) Expr { return compare(field, "<", value) }
+
+// Lte `field <= value`
+func Lte(field string, value interface{}) Expr { return compare(field, "<=", value) }
+
+// Between `field >= low and field <= high`
+func Between(field string, low, high interface{}) Expr {
+	return And(Gte(field, low), Lte(field, high))
+}
+
+// In `field in (values)`, list is a slice or an array of strings or numbers
+func In(field string, list interface{}) Expr { return contain(field, "in", list) }
+
+// NotIn `field not in (values)`
+func NotIn(field string, list interface{}) Expr { return contain(field, "not in", list) }
+
+// Include `field include (values)`, the array field has any of the values
+func Include(field string, list interface{}) Expr { return contain(field, "include", list) }
+
+// Exclude `field exclude (values)`, the array field has none of the values
+func Exclude(field string, list interface{}) Expr { return contain(field, "exclude", list) }
+
+// IncludeAll `field include all (values)`, the array field has all the values
+func IncludeAll(field string, list interface{}) Expr { return contain(field, "include all", list) }
+
+// And all the expressions match, the empty expressions are skipped
+func And(exprs ...Expr) Expr { return join(kindAnd, exprs) }
+
+// Or any of the expressions matches, the empty expressions are skipped
+func Or(exprs ...Expr) Expr { return join(kindOr, exprs) }
+
+// Not the expression does not match
+func Not(expr Expr) Expr {
+	if expr.err != nil {
+		return expr
+	}
+	if expr.kind == kindEmpty {
+		return Expr{err: fmt.Errorf("filter: not of the empty expression")}
+	}
+	return Expr{kind: kindNot, exprs: []Expr{expr}}
+}
+
+// And returns a new expression of e and the others
+func (e Expr) And(others ...Expr) Expr { return And(append([]Expr{e}, others...)...) }
+
+// Or returns a new expression of e or the others
+func (e Expr) Or(others ...Expr) Expr { return Or(append([]Expr{e}, others...)...) }
+
+// IsEmpty reports whether the expression is empty, which matches all the documents
+func (e Expr) IsEmpty() bool { return e.kind == kindEmpty && e.err == nil }
+
+// Err returns the first error of building the expression, such as an invalid field name or value
+func (e Expr) Err() error { return e.err }
+
+// Build renders the expression to the filter condition of the server, or returns the error of building it
+func (e Expr) Build() (string, error) {
+	if e.err != nil {
+		return "", e.err
+	}
+	var b strings.Builder
+	e.render(&b)
+	return b.String(), nil
+}
+
+// String renders the expression, or describes the error if it is invalid
+func (e Expr) String() string {
+	cond, err := e.Build()
+	if err != nil {
+		return fmt.Sprintf("<invalid filter: %v>", err)
+	}
+	return cond
+}
+
+func (e Expr) render(b *strings.Builder) {
+	switch e.kind {
+	case kindCmp:
+		b.WriteString(e.field)
+		b.WriteByte(' ')
+		b.WriteString(e.op)
+		b.WriteByte(' ')
+		if len(e.values) == 1 && !isList(e.op) {
+			writeValue(b, e.values[0])
+			return
+		}
+		b.WriteByte('(')
+		for i, v := range e.values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeValue(b, v)
+		}
+		b.WriteByte(')')
+	case kindAnd, kindOr:
+		sep := " and "
+		if e.kind == kindOr {
+			sep = " or "
+		}
+		for i, sub := range e.exprs {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			// and binds tighter than or, so only or needs the parentheses in and
+			if e.kind == kindAnd && sub.kind == kindOr {
+				b.WriteByte('(')
+				sub.render(b)
+				b.WriteByte(')')
+			} else {
+				sub.render(b)
+			}
+		}
+	case kindNot:
+		b.WriteString("not (")
+		e.exprs[0].render(b)
+		b.WriteByte(')')
+	}
+}
+
+func isList(op string) bool {
+	switch op {
+	case "in", "not in", "include", "exclude", "include all":
+		return true
+	}
+	return false
+}
+
+func writeValue(b *strings.Builder, v interface{}) {
+	if s, ok := v.(string); ok {
+		b.WriteString(Quote(s))
+		return
+	}
+	b.WriteString(string(v.(json.Number)))
+}
+
+// Quote returns the string literal of the filter, the backslashes and the double quotes are escaped.
+// The same escape is read by Parse and the tcvectordbtest fakes.
+func Quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func compare(field, op string, value interface{}) Expr {
+	if err := checkField(field); err != nil {
+		return Expr{err: err}
+	}
+	v, err := normalize(value)
+	if err != nil {
+		return Expr{err: fmt.Errorf("filter: field %s: %v", field, err)}
+	}
+	return Expr{kind: kindCmp, field: field, op: op, values: []interface{}{v}}
+}
+
+func contain(field, op string, list interface{}) Expr {
+	if err := checkField(field); err != nil {
+		return Expr{err: err}
+	}
+	rv := reflect.ValueOf(list)
+	if list == nil || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return Expr{err: fmt.Errorf("filter: field %s: %s requires a slice or an array, got %T", field, op, list)}
+	}
+	if rv.Len() == 0 {
+		return Expr{err: fmt.Errorf("filter: field %s: %s requires at least one value", field, op)}
+	}
+	values := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		v, err := normalize(rv.Index(i).Interface())
+		if err != nil {
+			return Expr{err: fmt.Errorf("filter: field %s: %v", field, err)}
+		}
+		values = append(values, v)
+	}
+	return Expr{kind: kindCmp, field: field, op: op, values: values}
+}
+
+func join(k kind, exprs []Expr) Expr {
+	joined := make([]Expr, 0, len(exprs))
+	for _, e := range exprs {
+		if e.err != nil {
+			return e
+		}
+		switch e.kind {
+		case kindEmpty:
+			continue
+		case k:
+			// flatten the nested expressions of the same kind, their slices are never modified
+			joined = append(joined, e.exprs...)
+		default:
+			joined = append(joined, e)
+		}
+	}
+	switch len(joined) {
+	case 0:
+		return Expr{}
+	case 1:
+		return joined[0]
+	}
+	return Expr{kind: k, exprs: joined}
+}
+
+// checkField checks the field name is an identifier, so it could not change the expression
+func checkField(field string) error {
+	if field == "" {
+		return fmt.Errorf("filter: the field name is empty")
+	}
+	for i, r := range field {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || !(r == '.' || (r >= '0' && r <= '9'))) {
+			return fmt.Errorf("filter: invalid field name %q", field)
+		}
+	}
+	return nil
+}
+
+// normalize converts the value to a string or a json.Number
+func normalize(value interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		if n, ok := value.(json.Number); ok {
+			if _, err := strconv.ParseFloat(string(n), 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", n)
+			}
+			return n, nil
+		}
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("invalid number %v", f)
+		}
+		return json.Number(strconv.FormatFloat(f, 'f', -1, rv.Type().Bits())), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T, which must be a string or a number", value)
+}
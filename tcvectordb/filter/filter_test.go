@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilterBuild(t *testing.T) {
+	for expect, expr := range map[string]Expr{
+		`author = "tom"`:                                   Eq("author", "tom"),
+		`author = "to\"m\\\" or page > 1"`:                 Eq("author", `to"m\" or page > 1`),
+		`page >= 10 and page <= 20.5`:                      Between("page", 10, 20.5),
+		`id in ("a", "b\"")`:                               In("id", []string{"a", `b"`}),
+		`tags include all (1, 2)`:                          IncludeAll("tags", [2]uint64{1, 2}),
+		`a = 1 and (b = 2 or c = 3) and d != "x"`:          And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3)), Ne("d", "x")),
+		`a = 1 or b = 2 and c < -3`:                        Or(Eq("a", 1), And(Eq("b", 2), Lt("c", -3))),
+		`not (a = 1 or b = 2) and tags exclude ("x")`:      Not(Eq("a", 1).Or(Eq("b", 2))).And(Exclude("tags", []string{"x"})),
+		`a = 1 and b = 2 and c = 3`:                        And(And(Eq("a", 1), Expr{}), And(Eq("b", 2), Eq("c", 3))),
+		`a.b = 1 and id not in (1) and tags include ("x")`: And(Eq("a.b", 1), NotIn("id", []int{1}), Include("tags", []string{"x"})),
+		``: And(Expr{}, Or()),
+	} {
+		cond, err := expr.Build()
+		if err != nil {
+			t.Fatalf("%s: %v", expect, err)
+		}
+		if cond != expect {
+			t.Fatalf("expect %s, got %s", expect, cond)
+		}
+	}
+
+	// And and Or never change the receiver
+	base := And(Eq("a", 1), Eq("b", 2))
+	left, right := base.And(Eq("c", 3)), base.Or(Eq("d", 4))
+	if base.String() != `a = 1 and b = 2` || left.String() != `a = 1 and b = 2 and c = 3` ||
+		right.String() != `a = 1 and b = 2 or d = 4` {
+		t.Fatalf("unexpected expressions %s, %s, %s", base, left, right)
+	}
+
+	for _, expr := range []Expr{
+		Eq(`a" or 1=1 or "`, 1),
+		Eq("a", struct{}{}),
+		In("a", []string{}),
+		In("a", "x"),
+		Gt("a", 1).And(Lt("b", []int{1})),
+		Not(Expr{}),
+	} {
+		if _, err := expr.Build(); err == nil {
+			t.Fatalf("expect error for %s", expr)
+		}
+	}
+}
+
+func TestFilterParse(t *testing.T) {
+	for cond, expect := range map[string]string{
+		`author="jerry" AND (page > 10 or tags include ("a"))`: `author = "jerry" and (page > 10 or tags include ("a"))`,
+		`not (a = 1) or b NOT IN ("x\"y", 2)`:                  `not (a = 1) or b not in ("x\"y", 2)`,
+		`tags include all (1,2) and c exclude ("z")`:           `tags include all (1, 2) and c exclude ("z")`,
+		`((a <= -1.5))`: `a <= -1.5`,
+		`  `:            ``,
+	} {
+		expr, err := Parse(cond)
+		if err != nil {
+			t.Fatalf("%s: %v", cond, err)
+		}
+		if expr.String() != expect {
+			t.Fatalf("%s: expect %s, got %s", cond, expect, expr)
+		}
+	}
+
+	for cond, pos := range map[string]int{
+		`a = `:                    4,
+		`a == 1`:                  3,
+		`a = 1 and`:               9,
+		`(a = 1`:                  6,
+		`a in (1, 2`:              10,
+		`a not like ("x")`:        6,
+		`a = "x`:                  4,
+		`a = "abc\"`:              4,
+		`a in ("x", "y\`:          11,
+		`a = 1 b = 2`:             6,
+		`1a = 1`:                  0,
+		`a = 1 or tags has ("x")`: 14,
+	} {
+		_, err := Parse(cond)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("%s: expect syntax error, got %v", cond, err)
+		}
+		if syntaxErr.Pos != pos {
+			t.Fatalf("%s: expect error at %d, got %v", cond, pos, err)
+		}
+	}
+}
+
+func TestFilterEval(t *testing.T) {
+	fields := map[string]interface{}{
+		"author": "tom",
+		"page":   json.Number("20"),
+		"big":    uint64(18446744073709551615),
+		"score":  float32(0.5),
+		"tags":   []interface{}{"a", "b"},
+		"ids":    []uint64{1, 2},
+	}
+	for cond, expect := range map[string]bool{
+		`author = "tom" and page > 10`:                true,
+		`author != "tom" or page <= 10`:               false,
+		`page = "20"`:                                 false,
+		`author > 1`:                                  false,
+		`big = 18446744073709551615`:                  true,
+		`big > 18446744073709551614`:                  true,
+		`score >= 0.5 and score < 0.6`:                true,
+		`author in ("jerry", "tom")`:                  true,
+		`author not in ("jerry", "tom")`:              false,
+		`tags include ("b", "c")`:                     true,
+		`tags include all ("a", "c")`:                 false,
+		`tags exclude ("c")`:                          true,
+		`ids include all (1, 2)`:                      true,
+		`author include ("tom")`:                      false,
+		`missing != 1`:                                false,
+		`not (missing = 1)`:                           true,
+		`author = "tom" and not (tags include ("a"))`: false,
+		``: true,
+	} {
+		expr, err := Parse(cond)
+		if err != nil {
+			t.Fatalf("%s: %v", cond, err)
+		}
+		if got := expr.Eval(fields); got != expect {
+			t.Fatalf("%s: expect %v, got %v", cond, expect, got)
+		}
+	}
+	if Eq("a", struct{}{}).Eval(fields) {
+		t.Fatal("the invalid expression matches")
+	}
+}
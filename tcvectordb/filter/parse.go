@@ -0,0 +1,264 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError the error of parsing the filter, Pos is the byte offset of the offending token in Input
+type SyntaxError struct {
+	Input string
+	Pos   int
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: syntax error at position %d of %q: %s", e.Pos, e.Input, e.Msg)
+}
+
+// Parse parses the filter condition as accepted by the server, like
+// `author="jerry" and (page > 10 or tags include ("a"))`, the empty condition returns the empty expression.
+// The error is a *SyntaxError.
+func Parse(cond string) (Expr, error) {
+	p := &parser{input: cond}
+	if p.peek() == "" {
+		return Expr{}, nil
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return Expr{}, err
+	}
+	if tok := p.peek(); tok != "" {
+		return Expr{}, p.errorf("unexpected %q", tok)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+	// start: the start of the last token consumed or peeked, where the errors are reported
+	start int
+	// unterminated: the last token is a string literal without the closing quote
+	unterminated bool
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Input: p.input, Pos: p.start, Msg: fmt.Sprintf(format, args...)}
+}
+
+// token returns the next token and its end position without consuming it
+func (p *parser) token() (string, int) {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	p.start = p.pos
+	p.unterminated = false
+	if p.pos >= len(p.input) {
+		return "", p.pos
+	}
+	start := p.pos
+	c := p.input[start]
+	switch {
+	case c == '(' || c == ')' || c == ',' || c == '=':
+		return p.input[start : start+1], start + 1
+	case c == '!' || c == '>' || c == '<':
+		if start+1 < len(p.input) && p.input[start+1] == '=' {
+			return p.input[start : start+2], start + 2
+		}
+		return p.input[start : start+1], start + 1
+	case c == '"':
+		end := start + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			// the quote after a trailing backslash is escaped, so it does not close the string
+			p.unterminated = true
+			return p.input[start:], len(p.input)
+		}
+		return p.input[start : end+1], end + 1
+	}
+	end := start
+	for end < len(p.input) {
+		r := rune(p.input[end])
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-' || r == '+') {
+			break
+		}
+		end++
+	}
+	if end == start {
+		end++
+	}
+	return p.input[start:end], end
+}
+
+func (p *parser) peek() string {
+	tok, _ := p.token()
+	return tok
+}
+
+func (p *parser) next() string {
+	tok, end := p.token()
+	p.pos = end
+	return tok
+}
+
+func keyword(tok, kw string) bool {
+	return strings.EqualFold(tok, kw)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	exprs, err := p.parseList("or", p.parseAnd)
+	if err != nil {
+		return Expr{}, err
+	}
+	return Or(exprs...), nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	exprs, err := p.parseList("and", p.parseUnary)
+	if err != nil {
+		return Expr{}, err
+	}
+	return And(exprs...), nil
+}
+
+// parseList parses the operands separated by the keyword
+func (p *parser) parseList(kw string, parse func() (Expr, error)) ([]Expr, error) {
+	var exprs []Expr
+	for {
+		expr, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !keyword(p.peek(), kw) {
+			return exprs, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.peek()
+	if keyword(tok, "not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return Expr{}, err
+		}
+		return Not(expr), nil
+	}
+	if tok == "(" {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return Expr{}, err
+		}
+		if tok := p.next(); tok != ")" {
+			return Expr{}, p.errorf("expect ), got %q", tok)
+		}
+		return expr, nil
+	}
+	return p.parseCmp()
+}
+
+func (p *parser) parseCmp() (Expr, error) {
+	field := p.next()
+	if field == "" || checkField(field) != nil {
+		return Expr{}, p.errorf("expect field name, got %q", field)
+	}
+	expr := Expr{kind: kindCmp, field: field}
+	op := p.next()
+	switch {
+	case op == "=" || op == "!=" || op == ">" || op == ">=" || op == "<" || op == "<=":
+		v, err := p.parseValue()
+		if err != nil {
+			return Expr{}, err
+		}
+		expr.op = op
+		expr.values = []interface{}{v}
+		return expr, nil
+	case keyword(op, "in"):
+		expr.op = "in"
+	case keyword(op, "not"):
+		if tok := p.next(); !keyword(tok, "in") {
+			return Expr{}, p.errorf("expect in after not, got %q", tok)
+		}
+		expr.op = "not in"
+	case keyword(op, "include"):
+		expr.op = "include"
+		if keyword(p.peek(), "all") {
+			p.next()
+			expr.op = "include all"
+		}
+	case keyword(op, "exclude"):
+		expr.op = "exclude"
+	default:
+		return Expr{}, p.errorf("unknown operator %q", op)
+	}
+	if tok := p.next(); tok != "(" {
+		return Expr{}, p.errorf("expect ( after %s, got %q", expr.op, tok)
+	}
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return Expr{}, err
+		}
+		expr.values = append(expr.values, v)
+		switch tok := p.next(); tok {
+		case ",":
+		case ")":
+			return expr, nil
+		default:
+			return Expr{}, p.errorf("expect , or ), got %q", tok)
+		}
+	}
+}
+
+// parseValue parses a string literal or a number to a string or a json.Number
+func (p *parser) parseValue() (interface{}, error) {
+	tok := p.next()
+	if strings.HasPrefix(tok, `"`) {
+		if p.unterminated {
+			return nil, p.errorf("unterminated string")
+		}
+		var b strings.Builder
+		for i := 1; i < len(tok)-1; i++ {
+			if tok[i] == '\\' && i+1 < len(tok)-1 {
+				i++
+			}
+			b.WriteByte(tok[i])
+		}
+		return b.String(), nil
+	}
+	if _, _, err := big.ParseFloat(tok, 10, 128, big.ToNearestEven); err != nil {
+		return nil, p.errorf("expect string or number, got %q", tok)
+	}
+	return json.Number(tok), nil
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FusionRoute one search of FusionSearch, which is one of Search by Vectors, SearchByText by Text,
+// or HybridSearch by Hybrid, against the Collection.
+type FusionRoute struct {
+	// Name: the key of the route in FusionDocument.Scores and Ranks, default the collection name
+	Name       string
+	Collection *Collection
+
+	Vectors [][]float32
+	Text    map[string][]string
+	// Params: the params of Search and SearchByText
+	Params *SearchDocumentParams
+	Hybrid *HybridSearchDocumentParams
+
+	// Weight: the weight of the route, default 1
+	Weight float32
+}
+
+type FusionSearchParams struct {
+	Routes []FusionRoute
+	// Method: RerankRrf sums weight/(RrfK+rank) of the routes, RerankWeighted sums weight*score of the routes,
+	// so the routes of RerankWeighted should have the comparable similarity scores, such as IP or COSINE, but not L2
+	Method RerankMethod
+	// RrfK: the k of RerankRrf, default 60
+	RrfK int32
+	// Limit: the number of the fused documents of each query, default 10
+	Limit int
+}
+
+// FusionDocument the fused document, Score is the fused score
+type FusionDocument struct {
+	Document
+	DatabaseName   string
+	CollectionName string
+	// Scores: the score of the document in each route which finds it, by the route name
+	Scores map[string]float32
+	// Ranks: the rank of the document starting at 1 in each route which finds it, by the route name
+	Ranks map[string]int
+}
+
+type FusionSearchResult struct {
+	// Warnings: the warnings of the routes by the route name
+	Warnings map[string]string
+	// Documents: the fused documents of each query in descending order of the score
+	Documents [][]FusionDocument
+}
+
+// FusionSearch runs the routes concurrently, possibly against different collections or databases,
+// and fuses their results into one ranked list for each query. All the routes must have the same number of queries,
+// the n-th query of every route belongs to the n-th list. The documents are the same if they have the same id
+// in the same collection. If any route fails, the others are canceled and the error is returned.
+func FusionSearch(ctx context.Context, params FusionSearchParams) (*FusionSearchResult, error) {
+	if len(params.Routes) == 0 {
+		return nil, fmt.Errorf("fusionSearch failed, the Routes is empty")
+	}
+	if params.Method != RerankRrf && params.Method != RerankWeighted {
+		return nil, fmt.Errorf("fusionSearch failed, unsupported method %q", params.Method)
+	}
+	names := make([]string, len(params.Routes))
+	seen := make(map[string]bool, len(params.Routes))
+	for i, route := range params.Routes {
+		if route.Collection == nil {
+			return nil, fmt.Errorf("fusionSearch failed, the Collection of route %d is nil", i)
+		}
+		names[i] = route.Name
+		if names[i] == "" {
+			names[i] = route.Collection.CollectionName
+		}
+		if seen[names[i]] {
+			return nil, fmt.Errorf("fusionSearch failed, the route name %s is duplicated, set the Name of the routes", names[i])
+		}
+		seen[names[i]] = true
+		set := 0
+		for _, ok := range []bool{route.Vectors != nil, route.Text != nil, route.Hybrid != nil} {
+			if ok {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("fusionSearch failed, route %s must set one of Vectors, Text and Hybrid", names[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]*SearchDocumentResult, len(params.Routes))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i := range params.Routes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := params.Routes[i].search(ctx)
+			if err != nil {
+				// the first error cancels the others, so their context errors are not reported
+				once.Do(func() {
+					firstErr = fmt.Errorf("fusionSearch route %s failed: %w", names[i], err)
+					cancel()
+				})
+				return
+			}
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	queries := len(results[0].Documents)
+	res := &FusionSearchResult{Warnings: make(map[string]string)}
+	for i, result := range results {
+		if len(result.Documents) != queries {
+			return nil, fmt.Errorf("fusionSearch failed, route %s has %d queries, but route %s has %d",
+				names[i], len(result.Documents), names[0], queries)
+		}
+		if result.Warning != "" {
+			res.Warnings[names[i]] = result.Warning
+		}
+	}
+	for q := 0; q < queries; q++ {
+		res.Documents = append(res.Documents, params.fuse(names, results, q))
+	}
+	return res, nil
+}
+
+func (r *FusionRoute) search(ctx context.Context) (*SearchDocumentResult, error) {
+	switch {
+	case r.Vectors != nil:
+		return r.Collection.Search(ctx, r.Vectors, r.Params)
+	case r.Text != nil:
+		return r.Collection.SearchByText(ctx, r.Text, r.Params)
+	}
+	return r.Collection.HybridSearch(ctx, *r.Hybrid)
+}
+
+// fuse merges the q-th result of the routes, the ties keep the order the documents are first found
+func (p *FusionSearchParams) fuse(names []string, results []*SearchDocumentResult, q int) []FusionDocument {
+	k := p.RrfK
+	if k <= 0 {
+		k = 60
+	}
+	limit := p.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	index := make(map[[3]string]int)
+	var fused []FusionDocument
+	for i, result := range results {
+		route := p.Routes[i]
+		weight := route.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		for rank, doc := range result.Documents[q] {
+			key := [3]string{route.Collection.DatabaseName, route.Collection.CollectionName, doc.Id}
+			n, ok := index[key]
+			if !ok {
+				n = len(fused)
+				index[key] = n
+				fd := FusionDocument{
+					Document:       doc,
+					DatabaseName:   route.Collection.DatabaseName,
+					CollectionName: route.Collection.CollectionName,
+					Scores:         make(map[string]float32),
+					Ranks:          make(map[string]int),
+				}
+				fd.Score = 0
+				fused = append(fused, fd)
+			}
+			fd := &fused[n]
+			fd.Scores[names[i]] = doc.Score
+			fd.Ranks[names[i]] = rank + 1
+			if p.Method == RerankRrf {
+				fd.Score += weight / float32(int(k)+rank+1)
+			} else {
+				fd.Score += weight * doc.Score
+			}
+		}
+	}
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused
+}
@@ -0,0 +1,121 @@
+package tcvectordb_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/tcvectordbtest"
+)
+
+func TestFusionSearch(t *testing.T) {
+	srv := tcvectordbtest.NewServer()
+	defer srv.Close()
+	cli, err := tcvectordb.NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	coll := newFusionCollection(t, cli, "db", []tcvectordb.Document{
+		{Id: "0001", Vector: []float32{1, 0}, SparseVector: []encoder.SparseVecItem{{TermId: 1, Score: 0.9}}},
+		{Id: "0002", Vector: []float32{0.6, 0.8}, SparseVector: []encoder.SparseVecItem{{TermId: 2, Score: 0.5}}},
+		{Id: "0003", Vector: []float32{0, 1}, SparseVector: []encoder.SparseVecItem{{TermId: 1, Score: 0.1}}},
+	})
+	coll2 := newFusionCollection(t, cli, "db2", []tcvectordb.Document{
+		{Id: "0001", Vector: []float32{0, 1}},
+		{Id: "0004", Vector: []float32{1, 0}},
+	})
+
+	// the same id in different collections are different documents
+	res, err := tcvectordb.FusionSearch(ctx, tcvectordb.FusionSearchParams{
+		Routes: []tcvectordb.FusionRoute{
+			{Name: "zh", Collection: coll, Vectors: [][]float32{{1, 0}}},
+			{Name: "en", Collection: coll2, Vectors: [][]float32{{1, 0}}},
+		},
+		Method: tcvectordb.RerankRrf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := res.Documents[0]
+	if len(docs) != 5 || docs[0].Id != "0001" || docs[0].DatabaseName != "db" || docs[1].Id != "0004" || docs[1].DatabaseName != "db2" {
+		t.Fatalf("unexpected rrf result %+v", docs)
+	}
+	if docs[0].Ranks["zh"] != 1 || docs[0].Scores["zh"] != 1 || len(docs[0].Scores) != 1 || docs[0].Score != float32(1)/61 {
+		t.Fatalf("unexpected component scores %+v", docs[0])
+	}
+
+	limit := 3
+	res, err = tcvectordb.FusionSearch(ctx, tcvectordb.FusionSearchParams{
+		Routes: []tcvectordb.FusionRoute{
+			{Name: "dense", Collection: coll, Vectors: [][]float32{{1, 0}}},
+			{Name: "sparse", Collection: coll, Weight: 2, Hybrid: &tcvectordb.HybridSearchDocumentParams{
+				Match: []*tcvectordb.MatchOption{{Data: []encoder.SparseVecItem{{TermId: 1, Score: 1}}}},
+			}},
+		},
+		Method: tcvectordb.RerankWeighted,
+		Limit:  limit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs = res.Documents[0]
+	if len(docs) != 3 || docs[0].Id != "0001" || docs[1].Id != "0002" || docs[2].Id != "0003" {
+		t.Fatalf("unexpected weighted result %+v", docs)
+	}
+	if _, ok := docs[1].Scores["sparse"]; ok || len(docs[0].Scores) != 2 || docs[0].Score < 2.79 || docs[0].Score > 2.81 {
+		t.Fatalf("unexpected component scores %+v %+v", docs[0], docs[1])
+	}
+
+	_, err = tcvectordb.FusionSearch(ctx, tcvectordb.FusionSearchParams{
+		Routes: []tcvectordb.FusionRoute{
+			{Collection: coll, Vectors: [][]float32{{1, 0}}},
+			{Collection: coll2, Vectors: [][]float32{{1, 0}}},
+		},
+		Method: tcvectordb.RerankRrf,
+	})
+	if err == nil {
+		t.Fatal("the duplicated route names are not rejected")
+	}
+	missing := cli.Database("db").Collection("missing")
+	_, err = tcvectordb.FusionSearch(ctx, tcvectordb.FusionSearchParams{
+		Routes: []tcvectordb.FusionRoute{
+			{Name: "zh", Collection: coll, Vectors: [][]float32{{1, 0}}},
+			{Name: "missing", Collection: missing, Vectors: [][]float32{{1, 0}}},
+		},
+		Method: tcvectordb.RerankRrf,
+	})
+	if err == nil || !strings.Contains(err.Error(), "route missing failed") {
+		t.Fatalf("the failed route is not reported, got %v", err)
+	}
+}
+
+// newFusionCollection creates the collection coll in the database with the cosine vector and the sparse vector indexes
+func newFusionCollection(t *testing.T, cli *tcvectordb.Client, database string, docs []tcvectordb.Document) *tcvectordb.Collection {
+	ctx := context.Background()
+	db, err := cli.CreateDatabase(ctx, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll, err := db.CreateCollection(ctx, "coll", 1, 1, "", tcvectordb.Indexes{
+		VectorIndex: []tcvectordb.VectorIndex{{
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   2,
+			MetricType:  tcvectordb.COSINE,
+		}},
+		SparseVectorIndex: []tcvectordb.SparseVectorIndex{{
+			FieldName: "sparse_vector", FieldType: tcvectordb.SparseVector,
+			IndexType: tcvectordb.SPARSE_INVERTED, MetricType: tcvectordb.IP,
+		}},
+		FilterIndex: []tcvectordb.FilterIndex{{FieldName: "id", FieldType: tcvectordb.String, IndexType: tcvectordb.PRIMARY}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = coll.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	return coll
+}
@@ -0,0 +1,84 @@
+package tcvectordb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/tcvectordbtest"
+)
+
+func TestHybridSearchMultiRoute(t *testing.T) {
+	srv := tcvectordbtest.NewServer()
+	defer srv.Close()
+	cli, err := tcvectordb.NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	db, err := cli.CreateDatabase(ctx, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll, err := db.CreateCollection(ctx, "coll", 1, 1, "", tcvectordb.Indexes{
+		VectorIndex: []tcvectordb.VectorIndex{{
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   2,
+			MetricType:  tcvectordb.IP,
+		}, {
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "title_vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   2,
+			MetricType:  tcvectordb.IP,
+		}},
+		SparseVectorIndex: []tcvectordb.SparseVectorIndex{{
+			FieldName: "sparse_vector", FieldType: tcvectordb.SparseVector,
+			IndexType: tcvectordb.SPARSE_INVERTED, MetricType: tcvectordb.IP,
+		}},
+		FilterIndex: []tcvectordb.FilterIndex{{FieldName: "id", FieldType: tcvectordb.String, IndexType: tcvectordb.PRIMARY}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []tcvectordb.Document{
+		{Id: "0001", Vector: []float32{1, 0}, SparseVector: []encoder.SparseVecItem{{TermId: 1, Score: 0.1}},
+			Fields: map[string]tcvectordb.Field{"title_vector": {Val: []float32{0, 1}}}},
+		{Id: "0002", Vector: []float32{0, 1}, SparseVector: []encoder.SparseVecItem{{TermId: 2, Score: 0.1}},
+			Fields: map[string]tcvectordb.Field{"title_vector": {Val: []float32{1, 0}}}},
+	}
+	if _, err = coll.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	limit := 1
+	res, err := coll.HybridSearch(ctx, tcvectordb.HybridSearchDocumentParams{
+		AnnParams: []*tcvectordb.AnnParam{
+			{FieldName: "vector", Data: [][]float32{{1, 0}, {0, 1}}},
+			{FieldName: "title_vector", Data: [][]float32{{1, 0}, {0, 1}}},
+		},
+		Match: []*tcvectordb.MatchOption{{Data: [][]encoder.SparseVecItem{{{TermId: 1, Score: 1}}, {{TermId: 1, Score: 1}}}}},
+		Rerank: &tcvectordb.RerankOption{
+			Method:    tcvectordb.RerankWeighted,
+			FieldList: []string{"vector", "title_vector", "sparse_vector"},
+			Weight:    []float32{0.2, 0.7, 0.1},
+		},
+		Limit: &limit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the title_vector route has the highest weight, so it decides the order of each query
+	if len(res.Documents) != 2 || res.Documents[0][0].Id != "0002" || res.Documents[1][0].Id != "0001" {
+		t.Fatalf("unexpected multi route result %+v", res.Documents)
+	}
+
+	// one ann and one match route need no rerank, as before the multiple routes
+	res, err = coll.HybridSearch(ctx, tcvectordb.HybridSearchDocumentParams{
+		AnnParams: []*tcvectordb.AnnParam{{FieldName: "vector", Data: []float32{1, 0}}},
+		Match:     []*tcvectordb.MatchOption{{Data: []encoder.SparseVecItem{{TermId: 1, Score: 1}}}},
+		Limit:     &limit,
+	})
+	if err != nil || len(res.Documents) != 1 || len(res.Documents[0]) != 1 {
+		t.Fatalf("unexpected ann and match result %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+)
+
+type ImportOption struct {
+	// Columns: rename the keys of the JSONL records or the CSV columns to the document fields,
+	// eg: {"doc_id": "id", "embedding": "vector"}. The column mapped to "-" is dropped.
+	Columns map[string]string
+	// VectorDelimiter: the delimiter of the vectors and arrays written as strings, default ",".
+	// The strings starting with "[" are parsed as the json arrays. The sparse vectors could be
+	// written as "termId:score" pairs, eg: "1:0.5,7:0.25".
+	VectorDelimiter string
+	// Comma: the field delimiter of CSV, default ','. The first CSV row is the header.
+	Comma rune
+	// StartOffset: skip the first StartOffset records, to resume the import from a checkpoint
+	StartOffset int64
+	// CheckpointInterval: the records between the checkpoints, default BatchSize * Workers
+	CheckpointInterval int
+	// OnCheckpoint: called with the offset when the records before it are all written successfully,
+	// the import could be resumed by setting StartOffset to it. Once a batch fails, the checkpoint
+	// does not advance any more, so the resumed import writes the failed records again.
+	OnCheckpoint func(offset int64)
+	// Validate: check the records against the indexes from DescribeCollection, and convert the
+	// values to the types of the filter indexes, eg: the CSV strings of the uint64 fields
+	Validate bool
+	// RecordsPerSecond: throttle the import, which waits for the next record as long as ctx allows,
+	// 0 means no limit
+	RecordsPerSecond float64
+	// BatchSize, Workers and BuildIndex: the options of the BulkWriter
+	BatchSize  int
+	Workers    int
+	BuildIndex *bool
+}
+
+// ImportResult the result of ImportJSONL and ImportCSV. The failed batches do not stop the import,
+// their documents are in FailedIds, and the error of the import is BulkResult.Err.
+type ImportResult struct {
+	// Records: the records sent in this import, the skipped ones are excluded
+	Records int64
+	// Offset: the records consumed from the input, including the skipped ones
+	Offset        int64
+	AffectedCount int
+	FailedIds     []string
+}
+
+// ImportJSONL streams the json records, one per line, into the collection. The keys id, vector and
+// sparse_vector are the document attributes, the others are the fields.
+func ImportJSONL(ctx context.Context, coll *Collection, r io.Reader, option *ImportOption) (*ImportResult, error) {
+	return importRecords(ctx, coll, option, jsonlRecords(bufio.NewReader(r)))
+}
+
+// jsonlRecords returns the iterator of the json records, the blank lines are skipped
+func jsonlRecords(reader *bufio.Reader) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) == 0 {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			record := make(map[string]interface{})
+			decoder := json.NewDecoder(bytes.NewReader(line))
+			decoder.UseNumber()
+			if err := decoder.Decode(&record); err != nil {
+				return nil, fmt.Errorf("invalid json: %v", err)
+			}
+			return record, nil
+		}
+	}
+}
+
+// ImportCSV streams the CSV rows into the collection, the first row is the header of the column names.
+// The empty cells are omitted.
+func ImportCSV(ctx context.Context, coll *Collection, r io.Reader, option *ImportOption) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	if option != nil && option.Comma != 0 {
+		reader.Comma = option.Comma
+	}
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read the csv header failed: %w", err)
+	}
+	return importRecords(ctx, coll, option, func() (map[string]interface{}, error) {
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		record := make(map[string]interface{}, len(header))
+		for i, value := range row {
+			if value != "" {
+				record[header[i]] = value
+			}
+		}
+		return record, nil
+	})
+}
+
+func importRecords(ctx context.Context, coll *Collection, option *ImportOption, next func() (map[string]interface{}, error)) (*ImportResult, error) {
+	opt := ImportOption{}
+	if option != nil {
+		opt = *option
+	}
+	if opt.VectorDelimiter == "" {
+		opt.VectorDelimiter = ","
+	}
+	writerOption := &BulkWriterOption{BatchSize: opt.BatchSize, Workers: opt.Workers, BuildIndex: opt.BuildIndex}
+	writer := coll.BulkWriter(ctx, writerOption)
+	if opt.CheckpointInterval <= 0 {
+		opt.CheckpointInterval = writer.option.BatchSize * writer.option.Workers
+	}
+
+	var schema *importSchema
+	if opt.Validate {
+		described, err := coll.describe(ctx)
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+		schema = newImportSchema(described)
+	}
+	var throttle *importThrottle
+	if opt.RecordsPerSecond > 0 {
+		throttle = newImportThrottle(opt.RecordsPerSecond)
+	}
+
+	result := new(ImportResult)
+	pending := 0
+	// failed: a batch failed, the checkpoint could not advance past its records
+	failed := false
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			failed = true
+		}
+		// the batch failures are collected by the writer, only the cancellation stops the import
+		return ctx.Err()
+	}
+	checkpoint := func() {
+		if !failed && opt.OnCheckpoint != nil {
+			opt.OnCheckpoint(result.Offset)
+		}
+	}
+	var importErr error
+	for {
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			importErr = fmt.Errorf("record %d: %w", result.Offset, err)
+			break
+		}
+		if result.Offset < opt.StartOffset {
+			result.Offset++
+			continue
+		}
+		doc, err := toImportDocument(record, &opt, schema)
+		if err != nil {
+			importErr = fmt.Errorf("record %d: %w", result.Offset, err)
+			break
+		}
+		if throttle != nil {
+			if importErr = throttle.wait(ctx); importErr != nil {
+				break
+			}
+		}
+		if importErr = writer.Upsert(doc); importErr != nil {
+			break
+		}
+		result.Offset++
+		result.Records++
+		if pending++; pending >= opt.CheckpointInterval {
+			pending = 0
+			if importErr = flush(); importErr != nil {
+				break
+			}
+			checkpoint()
+		}
+	}
+	if importErr == nil {
+		importErr = flush()
+		if importErr == nil {
+			checkpoint()
+		}
+	}
+	bulk, err := writer.Close()
+	result.AffectedCount, result.FailedIds = bulk.AffectedCount, bulk.FailedIds
+	if importErr != nil {
+		return result, importErr
+	}
+	return result, err
+}
+
+// importThrottle spaces the records by the rate. Unlike the client rate limits, it never fails
+// because of the ctx deadline, the import is slowed down instead.
+type importThrottle struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newImportThrottle(recordsPerSecond float64) *importThrottle {
+	return &importThrottle{interval: time.Duration(float64(time.Second) / recordsPerSecond)}
+}
+
+// wait blocks until the time of the next record, or ctx is done
+func (t *importThrottle) wait(ctx context.Context) error {
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// importSchema the indexes of the collection to validate the records
+type importSchema struct {
+	dimension uint32
+	sparse    bool
+	fields    map[string]FilterIndex
+}
+
+func newImportSchema(coll *Collection) *importSchema {
+	s := &importSchema{fields: make(map[string]FilterIndex), sparse: len(coll.Indexes.SparseVectorIndex) != 0}
+	for _, index := range coll.Indexes.VectorIndex {
+		s.dimension = index.Dimension
+	}
+	for _, index := range coll.Indexes.FilterIndex {
+		s.fields[index.FieldName] = index
+	}
+	return s
+}
+
+func toImportDocument(record map[string]interface{}, opt *ImportOption, schema *importSchema) (Document, error) {
+	doc := Document{Fields: make(map[string]Field)}
+	for key, value := range record {
+		name := key
+		if mapped, ok := opt.Columns[key]; ok {
+			name = mapped
+		}
+		var err error
+		switch name {
+		case "-":
+		case "id":
+			doc.Id = Field{Val: value}.String()
+		case "vector":
+			doc.Vector, err = parseImportVector(value, opt.VectorDelimiter)
+		case "sparse_vector":
+			doc.SparseVector, err = parseImportSparseVector(value, opt.VectorDelimiter)
+		default:
+			doc.Fields[name] = Field{Val: value}
+		}
+		if err != nil {
+			return doc, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	if schema == nil {
+		return doc, nil
+	}
+	if doc.Id == "" {
+		return doc, errors.New("the id is empty")
+	}
+	if schema.dimension != 0 && len(doc.Vector) != 0 && len(doc.Vector) != int(schema.dimension) {
+		return doc, fmt.Errorf("the vector dimension is %d, the collection requires %d", len(doc.Vector), schema.dimension)
+	}
+	if len(doc.SparseVector) != 0 && !schema.sparse {
+		return doc, errors.New("the collection has no sparse vector index")
+	}
+	for name, field := range doc.Fields {
+		index, ok := schema.fields[name]
+		if !ok {
+			continue
+		}
+		converted, err := convertImportField(field.Val, index, opt.VectorDelimiter)
+		if err != nil {
+			return doc, fmt.Errorf("%s: %w", name, err)
+		}
+		doc.Fields[name] = Field{Val: converted}
+	}
+	return doc, nil
+}
+
+// convertImportField converts the value to the type of the filter index
+func convertImportField(value interface{}, index FilterIndex, delimiter string) (interface{}, error) {
+	switch index.FieldType {
+	case Uint64:
+		switch v := value.(type) {
+		case json.Number:
+			return strconv.ParseUint(v.String(), 10, 64)
+		case string:
+			return strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		}
+	case String:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		}
+	case Array:
+		switch v := value.(type) {
+		case []interface{}:
+			out := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("the array item %v is not a string", item)
+				}
+				out = append(out, s)
+			}
+			return out, nil
+		case string:
+			v = strings.TrimSpace(v)
+			if strings.HasPrefix(v, "[") {
+				var out []string
+				if err := json.Unmarshal([]byte(v), &out); err != nil {
+					return nil, err
+				}
+				return out, nil
+			}
+			return strings.Split(v, delimiter), nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("cannot convert %v to %s", value, index.FieldType)
+}
+
+func parseImportVector(value interface{}, delimiter string) ([]float32, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "[") {
+			var vector []float32
+			if err := json.Unmarshal([]byte(v), &vector); err != nil {
+				return nil, err
+			}
+			return vector, nil
+		}
+		for _, s := range strings.Split(v, delimiter) {
+			items = append(items, strings.TrimSpace(s))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported vector %v", value)
+	}
+	vector := make([]float32, 0, len(items))
+	for _, item := range items {
+		f, err := parseImportFloat(item)
+		if err != nil {
+			return nil, err
+		}
+		vector = append(vector, float32(f))
+	}
+	return vector, nil
+}
+
+func parseImportSparseVector(value interface{}, delimiter string) ([]encoder.SparseVecItem, error) {
+	var pairs []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		pairs = v
+	case string:
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "[") {
+			decoder := json.NewDecoder(strings.NewReader(v))
+			decoder.UseNumber()
+			if err := decoder.Decode(&pairs); err != nil {
+				return nil, err
+			}
+			break
+		}
+		for _, s := range strings.Split(v, delimiter) {
+			pair := strings.SplitN(strings.TrimSpace(s), ":", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("invalid sparse vector item %q, which must be termId:score", s)
+			}
+			pairs = append(pairs, []interface{}{pair[0], pair[1]})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sparse vector %v", value)
+	}
+	out := make([]encoder.SparseVecItem, 0, len(pairs))
+	for _, item := range pairs {
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, fmt.Errorf("invalid sparse vector item %v, which must be [termId, score]", item)
+		}
+		term, err := parseImportFloat(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		score, err := parseImportFloat(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, encoder.SparseVecItem{TermId: int64(term), Score: float32(score)})
+	}
+	return out, nil
+}
+
+func parseImportFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case float64:
+		return n, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(n), 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
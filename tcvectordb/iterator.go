@@ -0,0 +1,243 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIteratorPositionLost is returned by Iterator.Err when the documents of the previous page are deleted,
+// along with the ones before them too many to search backward. Start a new iteration to continue.
+var ErrIteratorPositionLost = errors.New("iterator position lost")
+
+// iteratorMaxOverlap the pages searched backward for the documents of the previous page
+const iteratorMaxOverlap = 8
+
+type IterateParams struct {
+	Filter         *Filter
+	RetrieveVector bool
+	OutputFields   []string
+	// PageSize: the limit of each query, default 100
+	PageSize int64
+	// Limit: the max documents to iterate, default 0 means all
+	Limit int64
+	// DisablePrefetch: by default the next page is queried in background while the current page is consumed
+	DisablePrefetch bool
+}
+
+// Iterator pages through the documents matching the filter with Query:
+//
+//	it := coll.Iterate(ctx, &tcvectordb.IterateParams{Filter: tcvectordb.NewFilter(`page > 10`)})
+//	defer it.Close()
+//	for it.Next() {
+//		doc := it.Document()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+//
+// The pages are queried by offset. Each page overlaps the previous one and continues after the last document
+// of it still there, which is searched backward if the documents before it are deleted, so the deletes and
+// inserts during the iteration do not cause the documents to be skipped or repeated. If the whole previous page
+// and too many documents before it are deleted, the iteration stops with ErrIteratorPositionLost.
+// The documents inserted after the current position are iterated. The same applies to both transports.
+type Iterator struct {
+	coll   *Collection
+	ctx    context.Context
+	cancel context.CancelFunc
+	params IterateParams
+
+	// the state of the pages, owned by the fetching goroutine if prefetch is enabled
+	offset   int64
+	lastId   string
+	prevIds  map[string]bool
+	returned int64
+	done     bool
+
+	pages chan iteratorPage
+	page  []Document
+	pos   int
+	doc   Document
+	err   error
+}
+
+type iteratorPage struct {
+	docs []Document
+	err  error
+}
+
+// Iterate returns the iterator of the documents. It is released when Next returns false,
+// Close it if the iteration is stopped early
+func (c *Collection) Iterate(ctx context.Context, params *IterateParams) *Iterator {
+	it := &Iterator{coll: c}
+	if params != nil {
+		it.params = *params
+	}
+	if it.params.PageSize <= 0 {
+		it.params.PageSize = 100
+	}
+	it.ctx, it.cancel = context.WithCancel(ctx)
+	if !it.params.DisablePrefetch {
+		it.pages = make(chan iteratorPage, 1)
+		go it.prefetch()
+	}
+	return it
+}
+
+func (it *Iterator) prefetch() {
+	defer close(it.pages)
+	for !it.done {
+		docs, err := it.fetch()
+		select {
+		case it.pages <- iteratorPage{docs: docs, err: err}:
+		case <-it.ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Next moves to the next document, it returns false when all the documents are iterated or an error occurs
+func (it *Iterator) Next() bool {
+	if it.next() {
+		return true
+	}
+	it.cancel()
+	return false
+}
+
+func (it *Iterator) next() bool {
+	for it.pos >= len(it.page) {
+		if it.err != nil {
+			return false
+		}
+		if it.pages != nil {
+			page, ok := <-it.pages
+			if !ok {
+				return false
+			}
+			it.page, it.err = page.docs, page.err
+		} else {
+			if it.done {
+				return false
+			}
+			it.page, it.err = it.fetch()
+		}
+		it.pos = 0
+	}
+	it.doc = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+// Document returns the current document
+func (it *Iterator) Document() Document {
+	return it.doc
+}
+
+// Err returns the error which stops the iteration
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close stops the prefetch, it is a no-op after Next returns false
+func (it *Iterator) Close() {
+	it.cancel()
+}
+
+// fetch returns the next page of the unseen documents
+func (it *Iterator) fetch() ([]Document, error) {
+	for !it.done {
+		docs, start, err := it.query()
+		if err != nil {
+			it.done = true
+			return nil, err
+		}
+		page := make([]Document, 0, len(docs)-start)
+		for _, doc := range docs[start:] {
+			if !it.prevIds[doc.Id] {
+				page = append(page, doc)
+			}
+		}
+		if len(docs) != 0 {
+			it.lastId = docs[len(docs)-1].Id
+			it.prevIds = make(map[string]bool, len(docs))
+			for _, doc := range docs {
+				it.prevIds[doc.Id] = true
+			}
+		}
+		if it.params.Limit > 0 && it.returned+int64(len(page)) >= it.params.Limit {
+			page = page[:it.params.Limit-it.returned]
+			it.done = true
+		}
+		it.returned += int64(len(page))
+		if len(page) != 0 {
+			return page, nil
+		}
+	}
+	return nil, nil
+}
+
+// query returns the documents from the position before the current one, and where the unseen documents start.
+// The documents after the last one of the previous page still there are unseen. The window is expanded backward
+// until one of them is found, at most by iteratorMaxOverlap pages, then ErrIteratorPositionLost is returned.
+// From the offset 0 all the documents are unseen, as the ones seen before are deleted.
+func (it *Iterator) query() ([]Document, int, error) {
+	maxOverlap := it.params.PageSize * iteratorMaxOverlap
+	for overlap := it.params.PageSize/4 + 1; ; overlap *= 2 {
+		if overlap > maxOverlap {
+			overlap = maxOverlap
+		}
+		if overlap > it.offset {
+			overlap = it.offset
+		}
+		offset := it.offset - overlap
+		limit := it.params.PageSize + overlap
+		res, err := it.coll.Query(it.ctx, nil, &QueryDocumentParams{
+			Filter:         it.params.Filter,
+			RetrieveVector: it.params.RetrieveVector,
+			OutputFields:   it.params.OutputFields,
+			Offset:         offset,
+			Limit:          limit,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		docs := res.Documents
+		start := -1
+		for i, doc := range docs {
+			if it.prevIds[doc.Id] {
+				start = i + 1
+			}
+		}
+		if start == -1 && (it.lastId == "" || offset == 0) {
+			start = 0
+		}
+		if start != -1 {
+			it.offset = offset + int64(len(docs))
+			it.done = int64(len(docs)) < limit
+			return docs, start, nil
+		}
+		if overlap == maxOverlap {
+			return nil, 0, ErrIteratorPositionLost
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package tcvectordb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIteratorReleasedOnDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0,"count":2,"documents":[{"id":"0001"},{"id":"0002"}]}`))
+	}))
+	defer srv.Close()
+	cli, err := NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := cli.Database("db").Collection("coll")
+
+	for _, disablePrefetch := range []bool{false, true} {
+		it := coll.Iterate(context.Background(), &IterateParams{PageSize: 10, DisablePrefetch: disablePrefetch})
+		var n int
+		for it.Next() {
+			n++
+		}
+		if it.Err() != nil || n != 2 {
+			t.Fatalf("prefetch disabled %v: expect 2 documents, got %d, %v", disablePrefetch, n, it.Err())
+		}
+		if it.ctx.Err() != context.Canceled {
+			t.Fatalf("prefetch disabled %v: expect the context released after the iteration", disablePrefetch)
+		}
+	}
+}
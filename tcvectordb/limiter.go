@@ -0,0 +1,196 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OperationClass string
+
+const (
+	// OperationUpsert the write requests: upsert, update and delete documents
+	OperationUpsert OperationClass = "upsert"
+	// OperationSearch the search and hybridSearch requests
+	OperationSearch OperationClass = "search"
+	// OperationQuery the query requests
+	OperationQuery OperationClass = "query"
+	// OperationAdmin the database, collection, alias and index requests
+	OperationAdmin OperationClass = "admin"
+)
+
+// operationClassOf classify the http path or grpc method
+func operationClassOf(path string) OperationClass {
+	op := path[strings.LastIndex(path, "/")+1:]
+	if strings.HasPrefix(path, "/document/") || strings.HasPrefix(path, "/ai/documentSet/") {
+		switch op {
+		case "search", "hybridSearch":
+			return OperationSearch
+		case "query", "get", "getChunks":
+			return OperationQuery
+		default:
+			return OperationUpsert
+		}
+	}
+	return OperationAdmin
+}
+
+// RateLimit the client side limits of one operation class
+type RateLimit struct {
+	// RequestsPerSecond: the token bucket refill rate, 0 means no rate limit
+	RequestsPerSecond float64
+	// Burst: the token bucket size, default ceil(RequestsPerSecond)
+	Burst int
+	// MaxInFlight: the max concurrent requests, 0 means no limit
+	MaxInFlight int
+	// FailFast: return a RateLimitError immediately when over the limits, instead of waiting
+	FailFast bool
+}
+
+// ErrRateLimited matches the RateLimitError by errors.Is
+var ErrRateLimited = errors.New("client rate limited")
+
+// RateLimitError is returned when a request exceeds the client side limits with FailFast set,
+// or the context deadline comes before the request could be sent.
+type RateLimitError struct {
+	Class  OperationClass
+	Reason string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s request rate limited: %s", e.Class, e.Reason)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+type operationLimiter struct {
+	class    OperationClass
+	limit    RateLimit
+	inFlight chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the requests by operation class, it works as the innermost middleware
+type rateLimiter struct {
+	limiters map[OperationClass]*operationLimiter
+}
+
+func newRateLimiter(limits map[OperationClass]RateLimit) *rateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	l := &rateLimiter{limiters: make(map[OperationClass]*operationLimiter)}
+	for class, limit := range limits {
+		if limit.RequestsPerSecond > 0 && limit.Burst <= 0 {
+			limit.Burst = int(math.Ceil(limit.RequestsPerSecond))
+		}
+		ol := &operationLimiter{class: class, limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+		if limit.MaxInFlight > 0 {
+			ol.inFlight = make(chan struct{}, limit.MaxInFlight)
+		}
+		l.limiters[class] = ol
+	}
+	return l
+}
+
+// appendMiddleware adds the limiter after the user middlewares
+func (l *rateLimiter) appendMiddleware(middlewares []Middleware) []Middleware {
+	if l == nil {
+		return middlewares
+	}
+	return append(append([]Middleware(nil), middlewares...), l.middleware)
+}
+
+func (l *rateLimiter) middleware(ctx context.Context, path string, req, res interface{}, next Invoker) error {
+	ol, ok := l.limiters[operationClassOf(path)]
+	if !ok {
+		return next(ctx, path, req, res)
+	}
+	if err := ol.wait(ctx); err != nil {
+		return err
+	}
+	if ol.inFlight != nil {
+		if ol.limit.FailFast {
+			select {
+			case ol.inFlight <- struct{}{}:
+			default:
+				return &RateLimitError{Class: ol.class, Reason: "too many in-flight requests"}
+			}
+		} else {
+			select {
+			case ol.inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		defer func() { <-ol.inFlight }()
+	}
+	return next(ctx, path, req, res)
+}
+
+// wait takes a token from the bucket, waiting for the refill unless FailFast is set
+func (ol *operationLimiter) wait(ctx context.Context) error {
+	if ol.limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	ol.mu.Lock()
+	now := time.Now()
+	ol.tokens = math.Min(float64(ol.limit.Burst), ol.tokens+now.Sub(ol.last).Seconds()*ol.limit.RequestsPerSecond)
+	ol.last = now
+	if ol.tokens >= 1 {
+		ol.tokens--
+		ol.mu.Unlock()
+		return nil
+	}
+	if ol.limit.FailFast {
+		ol.mu.Unlock()
+		return &RateLimitError{Class: ol.class, Reason: "requests per second exceeded"}
+	}
+	delay := time.Duration((1 - ol.tokens) / ol.limit.RequestsPerSecond * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		ol.mu.Unlock()
+		return &RateLimitError{Class: ol.class, Reason: "context deadline is earlier than the next token"}
+	}
+	// reserve the token in advance, it is given back if the context is done before the wait ends
+	ol.tokens--
+	ol.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		ol.mu.Lock()
+		ol.tokens = math.Min(float64(ol.limit.Burst), ol.tokens+1)
+		ol.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// Logger the structured logger used by the sdk. The keyvals are alternating keys and values,
+// eg: Log(ctx, LogLevelDebug, "response", "path", "/document/search", "latency", time.Second).
+// The debug events are only sent when the client Debug mode is on.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, keyvals ...interface{})
+}
+
+// LoggerFunc adapts a function to Logger, eg: the Debugw/Infow style methods of structured loggers
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, keyvals ...interface{})
+
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, keyvals ...interface{}) {
+	f(ctx, level, msg, keyvals...)
+}
+
+type stdLogger struct {
+	logger   *log.Logger
+	minLevel LogLevel
+}
+
+// NewStdLogger adapts the standard library log.Logger, the events lower than minLevel are dropped.
+// The output looks like: [DEBUG] response path=/document/search status=200 latency=3ms
+func NewStdLogger(logger *log.Logger, minLevel LogLevel) Logger {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &stdLogger{logger: logger, minLevel: minLevel}
+}
+
+func (l *stdLogger) Log(ctx context.Context, level LogLevel, msg string, keyvals ...interface{}) {
+	if level < l.minLevel {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(level.String())
+	b.WriteString("] ")
+	b.WriteString(msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		b.WriteString(" ")
+		b.WriteString(fmt.Sprint(keyvals[i]))
+		b.WriteString("=")
+		if i+1 < len(keyvals) {
+			b.WriteString(fmt.Sprint(keyvals[i+1]))
+		}
+	}
+	l.logger.Print(b.String())
+}
+
+var defaultLogger = NewStdLogger(nil, LogLevelDebug)
+
+const (
+	redactedValue = "******"
+	// maxLogArrayLen the numeric arrays such as vectors longer than it are truncated in the logs
+	maxLogArrayLen = 8
+)
+
+// redactHeader returns a copy of the header with the credentials hidden
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted.Get("Authorization") != "" {
+		redacted.Set("Authorization", redactedValue)
+	}
+	return redacted
+}
+
+// redactBody truncates the vectors and sparse vectors in the json body to keep the logs small
+func redactBody(body []byte) string {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	res, err := json.Marshal(truncateArrays(v))
+	if err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	return string(res)
+}
+
+func truncateArrays(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = truncateArrays(item)
+		}
+		return val
+	case []interface{}:
+		if len(val) > maxLogArrayLen && isVectorLike(val) {
+			truncated := make([]interface{}, 0, maxLogArrayLen/2+1)
+			truncated = append(truncated, val[:maxLogArrayLen/2]...)
+			return append(truncated, fmt.Sprintf("...(%d items)", len(val)))
+		}
+		for i, item := range val {
+			val[i] = truncateArrays(item)
+		}
+		return val
+	}
+	return v
+}
+
+// isVectorLike judges the array holds numbers, or the [termId, score] pairs of a sparse vector
+func isVectorLike(items []interface{}) bool {
+	for _, item := range items {
+		switch v := item.(type) {
+		case json.Number:
+		case []interface{}:
+			if !isVectorLike(v) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Invoker sends the request to the server and decodes the response into res.
+// The path is the http path or the grpc method, eg: /document/search
+type Invoker func(ctx context.Context, path string, req, res interface{}) error
+
+// Middleware wraps every request of both the http and grpc transports, like the grpc unary interceptors.
+// It must call next to continue the request. req and res are the api structs on the http transport,
+// and the olama messages on the grpc transport.
+type Middleware func(ctx context.Context, path string, req, res interface{}, next Invoker) error
+
+// chainMiddlewares builds the invoker which runs the middlewares in order before final
+func chainMiddlewares(middlewares []Middleware, final Invoker) Invoker {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], final
+		final = func(ctx context.Context, path string, req, res interface{}) error {
+			return middleware(ctx, path, req, res, next)
+		}
+	}
+	return final
+}
+
+type headerKey struct{}
+
+// WithHeader returns a copy of ctx carrying the header, which is sent as the http header
+// or the grpc metadata of the requests made with the context.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	header := make(http.Header)
+	if parent, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		header = parent.Clone()
+	}
+	header.Set(key, value)
+	return context.WithValue(ctx, headerKey{}, header)
+}
+
+// HeaderFromContext returns the header value set by WithHeader
+func HeaderFromContext(ctx context.Context, key string) string {
+	if header, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		return header.Get(key)
+	}
+	return ""
+}
+
+func headersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headerKey{}).(http.Header)
+	return header
+}
+
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+	RequestIDHeader   = "X-Request-Id"
+)
+
+// TraceContextMiddleware propagates the W3C trace context. The extract function gets the
+// traceparent and tracestate of the current span from ctx, eg: from the opentelemetry propagator.
+// If it is nil or returns an empty traceparent, a new sampled traceparent is generated.
+func TraceContextMiddleware(extract func(ctx context.Context) (traceparent, tracestate string)) Middleware {
+	return func(ctx context.Context, path string, req, res interface{}, next Invoker) error {
+		if HeaderFromContext(ctx, TraceParentHeader) == "" {
+			var traceparent, tracestate string
+			if extract != nil {
+				traceparent, tracestate = extract(ctx)
+			}
+			if traceparent == "" {
+				traceparent = "00-" + randomHex(16) + "-" + randomHex(8) + "-01"
+			}
+			ctx = WithHeader(ctx, TraceParentHeader, traceparent)
+			if tracestate != "" {
+				ctx = WithHeader(ctx, TraceStateHeader, tracestate)
+			}
+		}
+		return next(ctx, path, req, res)
+	}
+}
+
+// RequestIDMiddleware sets a unique request id header for each request, default X-Request-Id.
+// The id already set by WithHeader is kept.
+func RequestIDMiddleware(header string, generate func() string) Middleware {
+	if header == "" {
+		header = RequestIDHeader
+	}
+	if generate == nil {
+		generate = func() string { return randomHex(16) }
+	}
+	return func(ctx context.Context, path string, req, res interface{}, next Invoker) error {
+		if HeaderFromContext(ctx, header) == "" {
+			ctx = WithHeader(ctx, header, generate())
+		}
+		return next(ctx, path, req, res)
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// DefaultLatencyBuckets the upper bounds of the latency histogram buckets
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second,
+}
+
+// LatencyHistogram the latency distribution of one path.
+// Counts[i] is the number of requests whose latency <= Buckets[i], the last one counts the rest.
+type LatencyHistogram struct {
+	Buckets []time.Duration
+	Counts  []uint64
+	Count   uint64
+	Errors  uint64
+	Sum     time.Duration
+}
+
+// LatencyMetrics collects the per-path latency histograms of the requests
+type LatencyMetrics struct {
+	buckets    []time.Duration
+	mu         sync.Mutex
+	histograms map[string]*LatencyHistogram
+}
+
+// NewLatencyMetrics create the metrics with the bucket upper bounds, default DefaultLatencyBuckets
+func NewLatencyMetrics(buckets []time.Duration) *LatencyMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	return &LatencyMetrics{
+		buckets:    buckets,
+		histograms: make(map[string]*LatencyHistogram),
+	}
+}
+
+// Middleware returns the middleware recording the latency of each request
+func (m *LatencyMetrics) Middleware() Middleware {
+	return func(ctx context.Context, path string, req, res interface{}, next Invoker) error {
+		start := time.Now()
+		err := next(ctx, path, req, res)
+		m.observe(path, time.Since(start), err)
+		return err
+	}
+}
+
+func (m *LatencyMetrics) observe(path string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.histograms[path]
+	if !ok {
+		h = &LatencyHistogram{Buckets: m.buckets, Counts: make([]uint64, len(m.buckets)+1)}
+		m.histograms[path] = h
+	}
+	i := sort.Search(len(m.buckets), func(i int) bool { return latency <= m.buckets[i] })
+	h.Counts[i]++
+	h.Count++
+	h.Sum += latency
+	if err != nil {
+		h.Errors++
+	}
+}
+
+// Snapshot returns a copy of the histograms by path
+func (m *LatencyMetrics) Snapshot() map[string]LatencyHistogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]LatencyHistogram, len(m.histograms))
+	for path, h := range m.histograms {
+		c := *h
+		c.Counts = append([]uint64(nil), h.Counts...)
+		snapshot[path] = c
+	}
+	return snapshot
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+// Read requests (query, search, hybridSearch, describe, list...) are retried by default,
+// write requests are only retried when RetryWrites is set.
+type RetryPolicy struct {
+	// MaxAttempts: the total attempts including the first one, default 3. Set 1 to disable retry.
+	MaxAttempts int
+	// InitialBackoff: the backoff before the first retry, default 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff: the upper limit of the backoff, default 2s
+	MaxBackoff time.Duration
+	// Multiplier: the backoff grows by this factor after each attempt, default 2. Set 1 for a constant backoff
+	Multiplier float64
+	// Jitter: the random fraction (0~1) applied to each backoff, default 0.2
+	Jitter float64
+	// NoJitter: apply no jitter to the backoff, as the zero Jitter means the default one
+	NoJitter bool
+	// RetryWrites: retry the write requests (upsert, update, delete, create, drop...) too, default false
+	RetryWrites bool
+	// Retryable: decides whether an error is transient, default DefaultRetryable
+	Retryable func(err error) bool
+}
+
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// readOperations the last path segment of the apis which are safe to retry
+var readOperations = map[string]bool{
+	"query":        true,
+	"search":       true,
+	"hybridSearch": true,
+	"describe":     true,
+	"list":         true,
+	"get":          true,
+	"getChunks":    true,
+	"get_version":  true,
+}
+
+// isReadPath judges whether the http path or the grpc full method is a read operation
+func isReadPath(path string) bool {
+	return readOperations[path[strings.LastIndex(path, "/")+1:]]
+}
+
+// DefaultRetryable reports whether err is transient: connection errors, http 5xx/429
+// and grpc UNAVAILABLE. Context cancellation is never retryable.
+func DefaultRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.StatusCode == 429 || serverErr.StatusCode/100 == 5
+	}
+	if isConnectionError(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func retryPolicyMerge(p RetryPolicy) RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = defaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = defaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = defaultRetryPolicy.MaxBackoff
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = defaultRetryPolicy.Multiplier
+	}
+	if p.NoJitter {
+		p.Jitter = 0
+	} else if p.Jitter == 0 {
+		p.Jitter = defaultRetryPolicy.Jitter
+	}
+	if p.Retryable == nil {
+		p.Retryable = DefaultRetryable
+	}
+	return p
+}
+
+// backoff returns the wait time before the given retry, starting from 1
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(retry-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// withRetry calls fn until it succeeds, returns a non-retryable error, or the attempts are exhausted.
+// The retries are logged as the debug events if logger is not nil.
+func withRetry(ctx context.Context, policy *RetryPolicy, logger Logger, path string, fn func(ctx context.Context) error) error {
+	attempts := 1
+	if policy != nil && (policy.RetryWrites || isReadPath(path)) {
+		attempts = policy.MaxAttempts
+	}
+	var err error
+	for i := 1; ; i++ {
+		err = fn(ctx)
+		if err == nil || i >= attempts || !policy.Retryable(err) {
+			return err
+		}
+		backoff := policy.backoff(i)
+		if logger != nil {
+			logger.Log(ctx, LogLevelDebug, "retry request", "path", path, "attempt", i, "backoff", backoff, "error", err)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
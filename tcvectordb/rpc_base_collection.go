@@ -2,8 +2,8 @@ package tcvectordb
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -21,10 +21,10 @@ type rpcImplementerCollection struct {
 func (r *rpcImplementerCollection) ExistsCollection(ctx context.Context, name string) (bool, error) {
 	res, err := r.DescribeCollection(ctx, name)
 	if err != nil {
-		if strings.Contains(err.Error(), strconv.Itoa(ERR_UNDEFINED_COLLECTION)) {
+		if errors.Is(err, ErrCollectionNotFound) {
 			return false, nil
 		}
-		return false, fmt.Errorf("get collection %s failed, err: %v", name, err.Error())
+		return false, fmt.Errorf("get collection %s failed, err: %w", name, err)
 	}
 	if res == nil {
 		return false, fmt.Errorf("get collection %s failed", name)
@@ -36,10 +36,10 @@ func (r *rpcImplementerCollection) CreateCollectionIfNotExists(ctx context.Conte
 	indexes Indexes, params ...*CreateCollectionParams) (*Collection, error) {
 	res, err := r.DescribeCollection(ctx, name)
 	if err != nil {
-		if strings.Contains(err.Error(), strconv.Itoa(ERR_UNDEFINED_COLLECTION)) {
+		if errors.Is(err, ErrCollectionNotFound) {
 			return r.CreateCollection(ctx, name, shardNum, replicasNum, description, indexes, params...)
 		}
-		return nil, fmt.Errorf("get collection %s failed, err: %v", name, err.Error())
+		return nil, fmt.Errorf("get collection %s failed, err: %w", name, err)
 	}
 	if res == nil {
 		return nil, fmt.Errorf("get collection %s failed", name)
@@ -51,6 +51,7 @@ func (r *rpcImplementerCollection) CreateCollection(ctx context.Context, name st
 	if r.database.IsAIDatabase() {
 		return nil, AIDbTypeError
 	}
+	forgetSchema(r.SdkClient, r.database.DatabaseName, name)
 	req := &olama.CreateCollectionRequest{
 		Database:    r.database.DatabaseName,
 		Collection:  name,
@@ -170,6 +171,7 @@ func (r *rpcImplementerCollection) DropCollection(ctx context.Context, name stri
 	if r.database.IsAIDatabase() {
 		return nil, AIDbTypeError
 	}
+	forgetSchema(r.SdkClient, r.database.DatabaseName, name)
 	req := &olama.DropCollectionRequest{
 		Database:   r.database.DatabaseName,
 		Collection: name,
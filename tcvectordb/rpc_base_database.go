@@ -20,7 +20,7 @@ type rpcImplementerDatabase struct {
 func (r *rpcImplementerDatabase) ExistsDatabase(ctx context.Context, name string) (bool, error) {
 	dbList, err := r.ListDatabase(ctx)
 	if err != nil {
-		return false, fmt.Errorf("judging whether the database exists failed. err is %v", err.Error())
+		return false, fmt.Errorf("judging whether the database exists failed. err is %w", err)
 	}
 	for _, db := range dbList.Databases {
 		if db.DatabaseName == name {
@@ -33,7 +33,7 @@ func (r *rpcImplementerDatabase) ExistsDatabase(ctx context.Context, name string
 func (r *rpcImplementerDatabase) CreateDatabaseIfNotExists(ctx context.Context, name string) (*CreateDatabaseResult, error) {
 	dbList, err := r.ListDatabase(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("judging whether the database exists failed. err is %v", err.Error())
+		return nil, fmt.Errorf("judging whether the database exists failed. err is %w", err)
 	}
 	for _, db := range dbList.Databases {
 		if db.DatabaseName == name {
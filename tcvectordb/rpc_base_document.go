@@ -172,6 +172,10 @@ func (r *rpcImplementerFlatDocument) Query(ctx context.Context, databaseName, co
 		req.Query.Offset = param.Offset
 		req.Query.Limit = param.Limit
 	}
+	if err := validateRequestSchema(ctx, r.SdkClient, databaseName, collectionName,
+		req.Query.Filter, req.Query.OutputFields); err != nil {
+		return nil, err
+	}
 	res, err := r.rpcClient.Query(ctx, req)
 	if err != nil {
 		return nil, err
@@ -229,75 +233,53 @@ func (r *rpcImplementerFlatDocument) HybridSearch(ctx context.Context, databaseN
 	req.Search.Ann = make([]*olama.AnnData, 0)
 	req.Search.Sparse = make([]*olama.SparseData, 0)
 
-	for i, annParam := range params.AnnParams {
-		fieldName := "vector"
-		if annParam.FieldName != "" {
-			fieldName = annParam.FieldName
+	routes, err := newHybridRoutes(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range routes.ann {
+		annData := &olama.AnnData{
+			FieldName: route.fieldName,
+			Data:      make([]*olama.VectorArray, 0, len(route.vectors)),
 		}
-		req.Search.Ann = append(req.Search.Ann, &olama.AnnData{
-			FieldName: fieldName,
-		})
-		if annParam.Limit != nil {
-			req.Search.Ann[i].Limit = uint32(*annParam.Limit)
+		if route.Limit != nil {
+			annData.Limit = uint32(*route.Limit)
 		}
-
-		vectorArray := make([]*olama.VectorArray, 0, len(req.Search.Vectors))
-		if vec, ok := annParam.Data.([]float32); ok {
-			vectorArray = append(vectorArray, &olama.VectorArray{Vector: vec})
-		} else {
-			return nil, fmt.Errorf("hybridSearch failed, because of AnnParam.Vectors field type, " +
-				"which must be []float32")
+		for _, vec := range route.vectors {
+			annData.Data = append(annData.Data, &olama.VectorArray{Vector: vec})
 		}
-
-		req.Search.Ann[i].Data = vectorArray
-
-		if annParam.Params != nil {
-			req.Search.Ann[i].Params = new(olama.SearchParams)
-			req.Search.Ann[i].Params.Nprobe = annParam.Params.Nprobe
-			req.Search.Ann[i].Params.Ef = annParam.Params.Ef
-			req.Search.Ann[i].Params.Radius = annParam.Params.Radius
+		if route.Params != nil {
+			annData.Params = new(olama.SearchParams)
+			annData.Params.Nprobe = route.Params.Nprobe
+			annData.Params.Ef = route.Params.Ef
+			annData.Params.Radius = route.Params.Radius
 		}
-		break
+		req.Search.Ann = append(req.Search.Ann, annData)
 	}
 
-	for i, matchParam := range params.Match {
-		fieldName := "sparse_vector"
-		if matchParam.FieldName != "" {
-			fieldName = matchParam.FieldName
+	for _, route := range routes.match {
+		sparseData := &olama.SparseData{
+			FieldName: route.fieldName,
+			Data:      make([]*olama.SparseVectorArray, 0, len(route.vectors)),
 		}
-		req.Search.Sparse = append(req.Search.Sparse, &olama.SparseData{
-			FieldName: fieldName,
-		})
-		if matchParam.Limit != nil {
-			req.Search.Ann[i].Limit = uint32(*matchParam.Limit)
+		if route.Limit != nil {
+			sparseData.Limit = uint32(*route.Limit)
 		}
-
-		sparseVectorArray := make([]*olama.SparseVectorArray, 0)
-
-		if svs, ok := matchParam.Data.([]encoder.SparseVecItem); ok {
-			data := make([]*olama.SparseVecItem, 0)
+		for _, svs := range route.vectors {
+			data := make([]*olama.SparseVecItem, 0, len(svs))
 			for _, sv := range svs {
 				data = append(data, &olama.SparseVecItem{
 					TermId: sv.TermId,
 					Score:  sv.Score,
 				})
 			}
-			sparseVectorArray = append(sparseVectorArray, &olama.SparseVectorArray{
-				SpVector: data,
-			})
-			req.Search.Sparse[i].Data = sparseVectorArray
-		} else {
-			return nil, fmt.Errorf("hybridSearch failed, because of Match.Data field type, " +
-				"which must be []encoder.SparseVecItem")
+			sparseData.Data = append(sparseData.Data, &olama.SparseVectorArray{SpVector: data})
 		}
-		break
+		req.Search.Sparse = append(req.Search.Sparse, sparseData)
 	}
 
 	if params.Rerank != nil {
 		req.Search.RerankParams = new(olama.RerankParams)
-		if len(params.Rerank.FieldList) != len(params.Rerank.Weight) {
-			return nil, fmt.Errorf("the length of fieldlist should be equal with the length of weights")
-		}
 		req.Search.RerankParams.Method = string(params.Rerank.Method)
 		req.Search.RerankParams.Weights = make(map[string]float32, 0)
 		for i, fieldName := range params.Rerank.FieldList {
@@ -309,8 +291,14 @@ func (r *rpcImplementerFlatDocument) HybridSearch(ctx context.Context, databaseN
 	req.Search.Filter = params.Filter.Cond()
 	req.Search.RetrieveVector = params.RetrieveVector
 	req.Search.Outputfields = params.OutputFields
-	req.Search.Limit = uint32(*params.Limit)
+	if params.Limit != nil {
+		req.Search.Limit = uint32(*params.Limit)
+	}
 
+	if err = validateRequestSchema(ctx, r.SdkClient, databaseName, collectionName,
+		req.Search.Filter, req.Search.Outputfields); err != nil {
+		return nil, err
+	}
 	res, err := r.rpcClient.HybridSearch(ctx, req)
 	if err != nil {
 		return nil, err
@@ -358,6 +346,9 @@ func (r *rpcImplementerFlatDocument) Delete(ctx context.Context, databaseName, c
 			Filter:      param.Filter.Cond(),
 		},
 	}
+	if err := validateRequestSchema(ctx, r.SdkClient, databaseName, collectionName, req.Query.Filter, nil); err != nil {
+		return nil, err
+	}
 	res, err := r.rpcClient.Dele(ctx, req)
 	if err != nil {
 		return nil, err
@@ -429,6 +420,9 @@ func (r *rpcImplementerFlatDocument) Update(ctx context.Context, databaseName, c
 			"which must be map[string]Field or map[string]interface{}")
 	}
 
+	if err := validateRequestSchema(ctx, r.SdkClient, databaseName, collectionName, req.Query.Filter, nil); err != nil {
+		return nil, err
+	}
 	res, err := r.rpcClient.Update(ctx, req)
 	if err != nil {
 		return nil, err
@@ -467,6 +461,10 @@ func (r *rpcImplementerFlatDocument) search(ctx context.Context, databaseName, c
 			}
 		}
 	}
+	if err := validateRequestSchema(ctx, r.SdkClient, databaseName, collectionName,
+		req.Search.Filter, req.Search.Outputfields); err != nil {
+		return nil, err
+	}
 	res, err := r.rpcClient.Search(ctx, req)
 	if err != nil {
 		return nil, err
@@ -2,16 +2,21 @@ package tcvectordb
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
 	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/olama"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type RpcClient struct {
@@ -20,59 +25,56 @@ type RpcClient struct {
 
 	httpImplementer SdkClient
 	rpcClient       olama.SearchEngineClient
-	cc              *grpc.ClientConn
+	conns           *rpcConnPool
 	url             string
-	username        string
-	key             string
+	credentials     CredentialProvider
+	schemas         schemaCache
 	option          ClientOption
 	debug           bool
 }
 
+// NewRpcClient new grpc client with url, username and api key.
+// The url could contain several endpoints separated by comma, eg: http://10.0.0.1,http://10.0.0.2
 func NewRpcClient(url, username, key string, option *ClientOption) (*RpcClient, error) {
 	if option == nil {
 		option = &defaultOption
 	}
 
-	var httpTarget string
-	var rpcTarget string
-	if strings.HasPrefix(url, "http://") {
-		httpTarget = url
-		rpcTarget = strings.TrimPrefix(url, "http://")
-		portIndex := strings.Index(rpcTarget, ":")
-		if portIndex == -1 {
-			rpcTarget += ":80"
-		}
-	} else if strings.HasPrefix(url, "https://") {
-		return nil, errors.Errorf("invalid url param with %v for not supporting https://", url)
-	} else {
-		httpTarget = "http://" + url
-		rpcTarget = url
+	credentials, err := newCredentials(username, key, option.Credentials)
+	if err != nil {
+		return nil, err
 	}
 
 	cli := new(RpcClient)
 	cli.url = url
-	cli.username = username
-	cli.key = key
+	cli.credentials = credentials
 	cli.debug = false
 	cli.option = optionMerge(*option)
 
-	cc, err := grpc.NewClient(rpcTarget,
-		grpc.WithUnaryInterceptor(newInterceptor(cli)),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(100*1024*1024)),
-		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(100*1024*1024)),
-		grpc.WithInitialWindowSize(100*1024*1024),
-		grpc.WithInitialConnWindowSize(100*1024*1024),
-	)
-	cli.cc = cc
+	var httpTargets []string
+	var rpcTargets []rpcTarget
+	for _, endpoint := range splitEndpoints(url) {
+		httpTarget, target := parseRpcTarget(endpoint, option.TLS != nil)
+		httpTargets = append(httpTargets, httpTarget)
+		rpcTargets = append(rpcTargets, target)
+	}
+	if len(rpcTargets) == 0 {
+		return nil, fmt.Errorf("invalid url param with: %s", url)
+	}
+
+	conns, err := newRpcConnPool(cli, rpcTargets, option.TLS)
 	if err != nil {
 		return nil, err
 	}
-	cli.rpcClient = olama.NewSearchEngineClient(cc)
+	cli.conns = conns
+	cli.rpcClient = olama.NewSearchEngineClient(conns)
 
-	httpc, err := NewClient(httpTarget, username, key, option)
+	// the http client is only the fallback of the ai database apis, it is not warmed up
+	httpOption := *option
+	httpOption.WarmUp = false
+	httpc, err := NewClient(strings.Join(httpTargets, ","), username, key, &httpOption)
 	if err != nil {
-		cc.Close()
+		conns.Close()
 		return nil, err
 	}
 	cli.httpImplementer = httpc
@@ -89,6 +91,9 @@ func NewRpcClient(url, username, key string, option *ClientOption) (*RpcClient,
 	cli.DatabaseInterface = databaseImpl
 	cli.FlatInterface = flatImpl
 
+	if cli.option.WarmUp {
+		conns.connect()
+	}
 	return cli, nil
 }
 
@@ -112,39 +117,243 @@ func (r *RpcClient) Debug(v bool) {
 
 func (r *RpcClient) Close() {
 	r.httpImplementer.Close()
-	r.cc.Close()
+	r.conns.Close()
+}
+
+// Ping checks the grpc connectivity state, then do a GetVersion round trip
+func (r *RpcClient) Ping(ctx context.Context) error {
+	if state := r.conns.state(); state != connectivity.Ready && state != connectivity.Idle && state != connectivity.Connecting {
+		return fmt.Errorf("grpc connection state is %s", state)
+	}
+	_, err := r.rpcClient.GetVersion(ctx, &olama.GetVersionRequest{})
+	return err
+}
+
+// WaitReady connects all endpoints and blocks until Ping succeeds or ctx is done
+func (r *RpcClient) WaitReady(ctx context.Context) error {
+	r.conns.connect()
+	return waitReady(ctx, r.Ping)
+}
+
+// ServerVersion get the kernel version of the server by the get_version rpc of olama.proto.
+// The http Client has no counterpart, the http route of it is not documented by the server.
+func (r *RpcClient) ServerVersion(ctx context.Context) (ServerVersion, error) {
+	res, err := r.rpcClient.GetVersion(ctx, &olama.GetVersionRequest{})
+	if err != nil {
+		return ServerVersion{}, err
+	}
+	return ParseKernalVersion(res.KernalVersion), nil
+}
+
+func (r *RpcClient) validateSchema(ctx context.Context, database, collection, cond string, outputFields []string) error {
+	if !r.option.ValidateSchema {
+		return nil
+	}
+	return r.schemas.validate(ctx, r.Database(database), collection, cond, outputFields)
 }
 
-func (r *RpcClient) attachCtx(ctx context.Context) context.Context {
-	auth := fmt.Sprintf("Bearer account=%s&api_key=%s", r.username, r.key)
-	md := metadata.Pairs("authorization", auth)
-	attached, _ := context.WithTimeout(ctx, r.option.Timeout)
-	attached = metadata.NewOutgoingContext(attached, md)
-	return attached
+func (r *RpcClient) forgetSchema(database, collection string) {
+	r.schemas.forget(database, collection)
+}
+
+func (r *RpcClient) attachCtx(ctx context.Context) (context.Context, error) {
+	cred, err := r.credentials.Credential(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	kv := []string{"authorization", cred.authorization()}
+	for k, values := range headersFromContext(ctx) {
+		if strings.EqualFold(k, "authorization") {
+			continue
+		}
+		for _, v := range values {
+			kv = append(kv, strings.ToLower(k), v)
+		}
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...), nil
 }
 
 func newInterceptor(client *RpcClient) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		ctx = client.attachCtx(ctx)
+		if _, ok := ctx.Deadline(); !ok && client.option.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, client.option.Timeout)
+			defer cancel()
+		}
+		var debugLogger Logger
 		if client.debug {
-			log.Printf("[DEBUG] REQUEST, Method: %s, Content: %v", method, req)
+			debugLogger = client.option.Logger
+			size, body := protoLogBody(req)
+			debugLogger.Log(ctx, LogLevelDebug, "request", "method", method, "size", size, "body", body)
 		}
-		err := invoker(ctx, method, req, reply, cc, opts...)
-		if codeGetter, ok := reply.(interface {
-			GetCode() int32
-			GetMsg() string
-		}); ok {
-			if codeGetter.GetCode() != 0 {
-				err = errors.Errorf("code: %d, message: %s", codeGetter.GetCode(), codeGetter.GetMsg())
+		return withRetry(ctx, client.option.RetryPolicy, debugLogger, method, func(ctx context.Context) error {
+			ctx, err := client.attachCtx(ctx)
+			if err != nil {
+				return err
+			}
+			start := time.Now()
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil {
+				if codeGetter, ok := reply.(interface {
+					GetCode() int32
+					GetMsg() string
+				}); ok && codeGetter.GetCode() != 0 {
+					err = &ServerError{Code: codeGetter.GetCode(), Message: codeGetter.GetMsg(), Path: method}
+				}
+			}
+			if client.debug {
+				keyvals := []interface{}{"method", method, "latency", time.Since(start)}
+				var serverErr *ServerError
+				if errors.As(err, &serverErr) {
+					keyvals = append(keyvals, "status", codes.OK.String(), "code", serverErr.Code, "msg", serverErr.Message)
+				} else if err != nil {
+					keyvals = append(keyvals, "status", status.Code(err).String(), "error", err)
+				} else {
+					keyvals = append(keyvals, "status", codes.OK.String())
+					size, body := protoLogBody(reply)
+					keyvals = append(keyvals, "code", 0, "size", size, "body", body)
+				}
+				client.option.Logger.Log(ctx, LogLevelDebug, "response", keyvals...)
 			}
+			return err
+		})
+	}
+}
+
+type rpcTarget struct {
+	addr   string
+	secure bool
+}
+
+// parseRpcTarget returns the http url for the http fallback and the grpc target of the endpoint.
+// https:// urls use tls, so do the urls without scheme when the tls option is set.
+func parseRpcTarget(url string, withTLS bool) (string, rpcTarget) {
+	if strings.HasPrefix(url, "http://") {
+		addr := strings.TrimPrefix(url, "http://")
+		if !strings.Contains(addr, ":") {
+			addr += ":80"
 		}
-		if client.debug {
+		return url, rpcTarget{addr: addr}
+	}
+	if strings.HasPrefix(url, "https://") {
+		addr := strings.TrimPrefix(url, "https://")
+		if !strings.Contains(addr, ":") {
+			addr += ":443"
+		}
+		return url, rpcTarget{addr: addr, secure: true}
+	}
+	if withTLS {
+		return "https://" + url, rpcTarget{addr: url, secure: true}
+	}
+	return "http://" + url, rpcTarget{addr: url}
+}
+
+// rpcConnPool implements grpc.ClientConnInterface over the connections of all endpoints.
+// Every call goes through the interceptor, and each attempt picks an endpoint by the selector.
+type rpcConnPool struct {
+	middlewares []Middleware
+	interceptor grpc.UnaryClientInterceptor
+	endpoints   *endpointSelector
+	conns       map[*endpoint]*grpc.ClientConn
+}
+
+func newRpcConnPool(cli *RpcClient, targets []rpcTarget, tlsOption *TLSOption) (*rpcConnPool, error) {
+	addrs := make([]string, 0, len(targets))
+	for _, target := range targets {
+		addrs = append(addrs, target.addr)
+	}
+	pool := &rpcConnPool{
+		middlewares: newRateLimiter(cli.option.RateLimits).appendMiddleware(cli.option.Middlewares),
+		interceptor: newInterceptor(cli),
+		endpoints:   newEndpointSelector(addrs, cli.option.LoadBalance, cli.option.EjectDuration, cli.option.CircuitBreaker),
+		conns:       make(map[*endpoint]*grpc.ClientConn),
+	}
+	for i, target := range targets {
+		transportCredentials := insecure.NewCredentials()
+		if target.secure {
+			tlsConfig, err := tlsOption.tlsConfig()
 			if err != nil {
-				log.Printf("[DEBUG] RESPONSE ERROR: %s", err.Error())
-			} else {
-				log.Printf("[DEBUG] RESPONSE: %v", reply)
+				pool.Close()
+				return nil, err
 			}
+			transportCredentials = credentials.NewTLS(tlsConfig)
+		}
+		dialOptions := []grpc.DialOption{
+			grpc.WithTransportCredentials(transportCredentials),
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(100 * 1024 * 1024)),
+			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(100 * 1024 * 1024)),
+			grpc.WithInitialWindowSize(100 * 1024 * 1024),
+			grpc.WithInitialConnWindowSize(100 * 1024 * 1024),
+		}
+		cc, err := grpc.NewClient(target.addr, append(dialOptions, cli.option.GrpcDialOptions...)...)
+		if err != nil {
+			pool.Close()
+			return nil, err
 		}
-		return err
+		pool.conns[pool.endpoints.endpoints[i]] = cc
+	}
+	return pool, nil
+}
+
+func (p *rpcConnPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	if len(p.middlewares) == 0 {
+		return p.interceptor(ctx, method, args, reply, nil, p.invoke, opts...)
+	}
+	return chainMiddlewares(p.middlewares, func(ctx context.Context, method string, req, res interface{}) error {
+		return p.interceptor(ctx, method, req, res, nil, p.invoke, opts...)
+	})(ctx, method, args, reply)
+}
+
+func (p *rpcConnPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.conns[p.endpoints.pick(!isReadPath(method), nil)].NewStream(ctx, desc, method, opts...)
+}
+
+// invoke is the grpc.UnaryInvoker which sends the request to the chosen endpoint
+func (p *rpcConnPool) invoke(ctx context.Context, method string, req, reply interface{}, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+	return p.endpoints.do(method, func(e *endpoint) error {
+		return p.conns[e].Invoke(ctx, method, req, reply, opts...)
+	})
+}
+
+// connect makes the idle connections start connecting
+func (p *rpcConnPool) connect() {
+	for _, cc := range p.conns {
+		cc.Connect()
+	}
+}
+
+// state returns the best connectivity state of the connections
+func (p *rpcConnPool) state() connectivity.State {
+	rank := map[connectivity.State]int{
+		connectivity.Ready:            4,
+		connectivity.Idle:             3,
+		connectivity.Connecting:       2,
+		connectivity.TransientFailure: 1,
+	}
+	best := connectivity.Shutdown
+	for _, cc := range p.conns {
+		if s := cc.GetState(); rank[s] > rank[best] {
+			best = s
+		}
+	}
+	return best
+}
+
+func (p *rpcConnPool) Close() {
+	for _, cc := range p.conns {
+		cc.Close()
+	}
+}
+
+// protoLogBody returns the size and the redacted json of the grpc message for logging
+func protoLogBody(m interface{}) (int, string) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return 0, fmt.Sprintf("%v", m)
+	}
+	body, err := protojson.Marshal(msg)
+	if err != nil {
+		return proto.Size(msg), fmt.Sprintf("%v", m)
 	}
+	return proto.Size(msg), redactBody(body)
 }
@@ -0,0 +1,210 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/filter"
+)
+
+// ErrSchemaMismatch is matched by SchemaError with errors.Is
+var ErrSchemaMismatch = errors.New("schema mismatch")
+
+// SchemaError is returned before sending the request when the filter or the output fields
+// do not match the indexes of the collection
+type SchemaError struct {
+	Collection string
+	Field      string
+	Message    string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("collection %s field %s: %s", e.Collection, e.Field, e.Message)
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaMismatch
+}
+
+// Validate checks the filter and the output fields against the indexes from DescribeCollection:
+// the filter must be valid and use the fields with filter index only, the string literals are for the string
+// fields and the numbers for the uint64 fields, include, exclude and include all are for the array fields.
+// The output fields must be known to the collection: the indexed fields, id, vector, sparse_vector, score
+// and the embedding field, the fields stored without any index are unknown to DescribeCollection and rejected.
+// The errors are *filter.SyntaxError or *SchemaError.
+func (c *Collection) Validate(ctx context.Context, f *Filter, outputFields []string) error {
+	described, err := c.describe(ctx)
+	if err != nil {
+		return err
+	}
+	return validateSchema(described, f.Cond(), outputFields)
+}
+
+func validateSchema(coll *Collection, cond string, outputFields []string) error {
+	expr, err := filter.Parse(cond)
+	if err != nil {
+		return err
+	}
+	indexes := make(map[string]FilterIndex)
+	for _, index := range coll.Indexes.FilterIndex {
+		indexes[index.FieldName] = index
+	}
+	schemaErr := func(field, format string, args ...interface{}) error {
+		return &SchemaError{Collection: coll.CollectionName, Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+	for _, cond := range expr.Conditions() {
+		index, ok := indexes[cond.Field]
+		if !ok || (index.IndexType != FILTER && index.IndexType != PRIMARY) {
+			return schemaErr(cond.Field, "has no filter index")
+		}
+		arrayOp := cond.Op == "include" || cond.Op == "exclude" || cond.Op == "include all"
+		elemType := index.FieldType
+		if index.FieldType == Array {
+			if !arrayOp {
+				return schemaErr(cond.Field, "is an array, which supports include, exclude and include all only")
+			}
+			elemType = index.ElemType
+		} else if arrayOp {
+			return schemaErr(cond.Field, "is %s, %s requires an array field", index.FieldType, cond.Op)
+		}
+		for _, v := range cond.Values {
+			_, isString := v.(string)
+			switch {
+			case elemType == String && !isString:
+				return schemaErr(cond.Field, "is string, but compared with number %v", v)
+			case elemType == Uint64 && isString:
+				return schemaErr(cond.Field, "is uint64, but compared with string %q", v)
+			}
+		}
+	}
+
+	known := map[string]bool{"id": true, "vector": true, "sparse_vector": true, "score": true}
+	for _, index := range coll.Indexes.FilterIndex {
+		known[index.FieldName] = true
+	}
+	for _, index := range coll.Indexes.VectorIndex {
+		known[index.FieldName] = true
+	}
+	for _, index := range coll.Indexes.SparseVectorIndex {
+		known[index.FieldName] = true
+	}
+	if coll.Embedding.Field != "" {
+		known[coll.Embedding.Field] = true
+	}
+	for _, field := range outputFields {
+		switch {
+		case field == "" || strings.IndexFunc(field, malformedRune) >= 0:
+			return schemaErr(field, "is not a valid field name")
+		case !known[field]:
+			return schemaErr(field, "is not in the collection schema")
+		}
+	}
+	return nil
+}
+
+// malformedRune the spaces, quotes and control characters could not be in a field name
+func malformedRune(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r) || r == '"' || r == '\''
+}
+
+const (
+	// schemaCacheTTL the described collections are validated against for this long, the indexes
+	// could be changed by the other clients
+	schemaCacheTTL = time.Minute
+	// schemaFailureTTL the describe failures skip the validation for this long, rather than
+	// describing again on each request
+	schemaFailureTTL = 5 * time.Second
+)
+
+// schemaCache caches the described collections for the schema validation by database and collection
+type schemaCache struct {
+	mu      sync.Mutex
+	entries map[[2]string]*schemaEntry
+}
+
+type schemaEntry struct {
+	collection *Collection // nil if the describe failed
+	expires    time.Time
+}
+
+// validate describes the collection once in schemaCacheTTL. The describe errors skip the validation,
+// so the request reports them, and are cached for schemaFailureTTL.
+func (c *schemaCache) validate(ctx context.Context, db *Database, collection, cond string, outputFields []string) error {
+	key := [2]string{db.DatabaseName, collection}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		entry = &schemaEntry{expires: time.Now().Add(schemaCacheTTL)}
+		res, err := db.DescribeCollection(ctx, collection)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			entry.expires = time.Now().Add(schemaFailureTTL)
+		} else {
+			entry.collection = &res.Collection
+		}
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[[2]string]*schemaEntry)
+		}
+		c.entries[key] = entry
+		c.mu.Unlock()
+	}
+	if entry.collection == nil {
+		return nil
+	}
+	return validateSchema(entry.collection, cond, outputFields)
+}
+
+// forget removes the collection, which is created or dropped
+func (c *schemaCache) forget(database, collection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, [2]string{database, collection})
+}
+
+type schemaValidator interface {
+	validateSchema(ctx context.Context, database, collection, cond string, outputFields []string) error
+	forgetSchema(database, collection string)
+}
+
+// validateRequestSchema fails early if the filter or the output fields mismatch the schema of the collection,
+// when the ValidateSchema option of cli is set
+func validateRequestSchema(ctx context.Context, cli SdkClient, database, collection, cond string, outputFields []string) error {
+	if validator, ok := cli.(schemaValidator); ok {
+		return validator.validateSchema(ctx, database, collection, cond, outputFields)
+	}
+	return nil
+}
+
+// forgetSchema removes the cached schema of the collection
+func forgetSchema(cli SdkClient, database, collection string) {
+	if validator, ok := cli.(schemaValidator); ok {
+		validator.forgetSchema(database, collection)
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordbtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// CassetteMode decides whether the cassette calls the real server
+type CassetteMode int
+
+const (
+	// ModeReplay answers the requests from the cassette file and never calls the server
+	ModeReplay CassetteMode = iota
+	// ModeRecord calls the real server and records the interactions, which are written by Save
+	ModeRecord
+)
+
+// ErrCassetteMiss the request is not found in the cassette while replaying
+var ErrCassetteMiss = errors.New("no interaction recorded for the request")
+
+const scrubbedValue = "******"
+
+// Interaction is a recorded request and response pair. The bodies are the normalized json,
+// and the grpc messages are recorded as their protojson form.
+type Interaction struct {
+	// Path: the url path of the http request, or the full method of the grpc request
+	Path     string          `json:"path"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Status   int             `json:"status,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Body: the http response body which is not json
+	Body string `json:"body,omitempty"`
+	// GrpcCode and Error: the status of the failed grpc request
+	GrpcCode codes.Code `json:"grpc_code,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Cassette records the requests to the real server into a file and replays them offline.
+// It is the http.RoundTripper for ClientOption.Transport, and UnaryClientInterceptor is the
+// equivalent for RpcClient, installed by ClientOption.GrpcDialOptions:
+//
+//	cassette, _ := tcvectordbtest.NewCassette("testdata/search.json", tcvectordbtest.ModeReplay)
+//	defer cassette.Save()
+//	cli, _ := tcvectordb.NewClient(url, "root", "key", &tcvectordb.ClientOption{Transport: cassette})
+//
+// The requests are matched by the path and the normalized body, the authorization header is never recorded.
+type Cassette struct {
+	// Transport: the transport to the real server while recording, default http.DefaultTransport
+	Transport http.RoundTripper
+	// Secrets: the values replaced by ****** in the recorded bodies, the replayed requests are scrubbed the same way
+	Secrets []string
+
+	path         string
+	mode         CassetteMode
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+type cassetteFile struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// NewCassette loads the cassette file for ModeReplay, or prepares an empty cassette for ModeRecord
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode}
+	if mode == ModeRecord {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file cassetteFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %v", path, err)
+	}
+	for _, interaction := range file.Interactions {
+		// the bodies are indented in the file
+		for _, raw := range []*json.RawMessage{&interaction.Request, &interaction.Response} {
+			buf := new(bytes.Buffer)
+			if len(*raw) != 0 && json.Compact(buf, *raw) == nil {
+				*raw = buf.Bytes()
+			}
+		}
+	}
+	c.interactions = file.Interactions
+	c.used = make([]bool, len(file.Interactions))
+	return c, nil
+}
+
+// Mode returns the mode of the cassette
+func (c *Cassette) Mode() CassetteMode {
+	return c.mode
+}
+
+// Interactions returns the recorded or loaded interactions
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file, it does nothing while replaying
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	reqBody := c.normalize(body)
+
+	if c.mode != ModeRecord {
+		found, err := c.find(req.URL.Path, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		resBody := []byte(found.Body)
+		if len(found.Response) != 0 {
+			resBody = found.Response
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", found.Status, http.StatusText(found.Status)),
+			StatusCode:    found.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(bytes.NewReader(resBody)),
+			ContentLength: int64(len(resBody)),
+			Request:       req,
+		}, nil
+	}
+
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	recorded := &Interaction{Path: req.URL.Path, Request: reqBody, Status: res.StatusCode}
+	if normalized := c.normalize(resBody); json.Valid(normalized) {
+		recorded.Response = normalized
+	} else {
+		recorded.Body = string(normalized)
+	}
+	c.record(recorded)
+	return res, nil
+}
+
+// UnaryClientInterceptor implements grpc.UnaryClientInterceptor, eg:
+//
+//	option.GrpcDialOptions = []grpc.DialOption{grpc.WithChainUnaryInterceptor(cassette.UnaryClientInterceptor)}
+func (c *Cassette) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	reqBody, err := c.marshalProto(req)
+	if err != nil {
+		return err
+	}
+	if c.mode != ModeRecord {
+		found, err := c.find(method, reqBody)
+		if err != nil {
+			return err
+		}
+		if found.GrpcCode != codes.OK {
+			return status.Error(found.GrpcCode, found.Error)
+		}
+		msg, ok := reply.(proto.Message)
+		if !ok {
+			return fmt.Errorf("the reply of %s is not a proto message", method)
+		}
+		return protojson.Unmarshal(found.Response, msg)
+	}
+
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	recorded := &Interaction{Path: method, Request: reqBody}
+	if err != nil {
+		s, ok := status.FromError(err)
+		if !ok || s.Code() == codes.Unavailable || s.Code() == codes.DeadlineExceeded || s.Code() == codes.Canceled {
+			// the transport failures are not recorded, like the http ones
+			return err
+		}
+		recorded.GrpcCode, recorded.Error = s.Code(), c.scrub(s.Message())
+	} else if recorded.Response, err = c.marshalProto(reply); err != nil {
+		return err
+	}
+	c.record(recorded)
+	return nil
+}
+
+// DialOptions returns the grpc dial options which install the cassette
+func (c *Cassette) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor)}
+}
+
+func (c *Cassette) record(interaction *Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction)
+	c.used = append(c.used, true)
+}
+
+// find returns the first unused interaction matching the request, the repeated requests
+// such as the retries reuse the last matched one after all matches are used.
+func (c *Cassette) find(path string, body []byte) (*Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var last *Interaction
+	for i, interaction := range c.interactions {
+		if interaction.Path != path || !bytes.Equal(interaction.Request, body) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return interaction, nil
+		}
+		last = interaction
+	}
+	if last != nil {
+		return last, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, path, body)
+}
+
+func (c *Cassette) marshalProto(m interface{}) (json.RawMessage, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", m)
+	}
+	body, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return c.normalize(body), nil
+}
+
+// normalize scrubs the secrets and re-encodes the json body with the sorted keys and without spaces,
+// the body which is not json is returned scrubbed.
+func (c *Cassette) normalize(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return []byte(c.scrub(string(body)))
+	}
+	v = c.scrubValue(v)
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return []byte(c.scrub(string(body)))
+	}
+	return bytes.TrimSpace(buf.Bytes())
+}
+
+func (c *Cassette) scrubValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = c.scrubValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = c.scrubValue(item)
+		}
+	case string:
+		return c.scrub(val)
+	}
+	return v
+}
+
+func (c *Cassette) scrub(s string) string {
+	for _, secret := range c.Secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, scrubbedValue)
+		}
+	}
+	return s
+}
@@ -0,0 +1,279 @@
+package tcvectordbtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+)
+
+func TestCassetteHttp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	srv := NewServer()
+	recorder, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Secrets = []string{"jerry"}
+	cli, err := tcvectordb.NewClient(srv.URL, "root", "key", &tcvectordb.ClientOption{Transport: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := createTestCollection(t, cli, tcvectordb.L2)
+	recorded, err := coll.Search(ctx, [][]float32{{1, 0}}, &tcvectordb.SearchDocumentParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.Close()
+	srv.Close()
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Bearer") || strings.Contains(string(data), "jerry") {
+		t.Fatalf("the secrets are recorded: %s", data)
+	}
+
+	player, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.Secrets = []string{"jerry"}
+	cli, err = tcvectordb.NewClient("http://127.0.0.1:1", "other", "secret", &tcvectordb.ClientOption{Transport: player})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	replayed, err := cli.Database("db").Collection("coll").Search(ctx, [][]float32{{1, 0}}, &tcvectordb.SearchDocumentParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed.Documents[0]) != 2 || replayed.Documents[0][0].Id != recorded.Documents[0][0].Id ||
+		replayed.Documents[0][0].Score != recorded.Documents[0][0].Score {
+		t.Fatalf("unexpected replayed result %+v", replayed.Documents)
+	}
+	if replayed.Documents[0][0].Fields["author"].String() != "******" {
+		t.Fatalf("the secret is not scrubbed: %v", replayed.Documents[0][0].Fields["author"])
+	}
+	_, err = cli.Database("db").Collection("coll").Search(ctx, [][]float32{{0, 1}})
+	if !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expect ErrCassetteMiss, got %v", err)
+	}
+}
+
+func TestCassetteGrpc(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	srv := NewRpcServer()
+	recorder, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := srv.NewClient("root", "key", &tcvectordb.ClientOption{GrpcDialOptions: recorder.DialOptions()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := createTestCollection(t, cli, tcvectordb.IP)
+	recorded, err := coll.Query(ctx, []string{"0001", "0002"}, &tcvectordb.QueryDocumentParams{RetrieveVector: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, notFound := cli.Database("db").Collection("none").Query(ctx, []string{"0001"})
+	cli.Close()
+	srv.Close()
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err = tcvectordb.NewRpcClient("passthrough:///replay", "root", "key", &tcvectordb.ClientOption{GrpcDialOptions: player.DialOptions()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	replayed, err := cli.Database("db").Collection("coll").Query(ctx, []string{"0001", "0002"}, &tcvectordb.QueryDocumentParams{RetrieveVector: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Total != recorded.Total || len(replayed.Documents) != 2 || len(replayed.Documents[1].Vector) != 2 ||
+		replayed.Documents[1].Fields["author"].String() != recorded.Documents[1].Fields["author"].String() {
+		t.Fatalf("unexpected replayed result %+v", replayed.Documents)
+	}
+	_, err = cli.Database("db").Collection("none").Query(ctx, []string{"0001"})
+	if !errors.Is(notFound, tcvectordb.ErrCollectionNotFound) || !errors.Is(err, tcvectordb.ErrCollectionNotFound) {
+		t.Fatalf("expect ErrCollectionNotFound, got %v and %v", notFound, err)
+	}
+}
+
+func TestCassetteReplayMiss(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cassette.json")
+	ctx := context.Background()
+
+	if _, err := NewCassette(filepath.Join(dir, "missing.json"), ModeReplay); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expect the missing cassette file reported, got %v", err)
+	}
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCassette(path, ModeReplay); err == nil {
+		t.Fatal("expect the invalid cassette file rejected")
+	}
+
+	srv := NewServer()
+	recorder, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := tcvectordb.NewClient(srv.URL, "root", "key", &tcvectordb.ClientOption{Transport: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.CreateDatabase(ctx, "db"); err != nil {
+		t.Fatal(err)
+	}
+	cli.Close()
+	srv.Close()
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err = tcvectordb.NewClient("http://127.0.0.1:1", "root", "key", &tcvectordb.ClientOption{
+		Transport: player, RetryPolicy: &tcvectordb.RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	// the repeated request reuses the recorded one
+	for i := 0; i < 2; i++ {
+		if _, err = cli.CreateDatabase(ctx, "db"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the same path with another body, and another path
+	if _, err = cli.CreateDatabase(ctx, "db2"); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expect ErrCassetteMiss of the other body, got %v", err)
+	}
+	if _, err = cli.ListDatabase(ctx); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expect ErrCassetteMiss of the other path, got %v", err)
+	}
+
+	rpcCli, err := tcvectordb.NewRpcClient("passthrough:///replay", "root", "key", &tcvectordb.ClientOption{
+		GrpcDialOptions: player.DialOptions(), RetryPolicy: &tcvectordb.RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcCli.Close()
+	if _, err = rpcCli.ListDatabase(ctx); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expect ErrCassetteMiss of the grpc request, got %v", err)
+	}
+}
+
+func TestCassetteScrubbing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+	secrets := []string{"key-secret", "jerry"}
+
+	httpSrv := NewServer()
+	rpcSrv := NewRpcServerWithStore(httpSrv.Store)
+	recorder, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Secrets = secrets
+	var sent http.Header
+	recorder.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req.Header.Clone()
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	option := tcvectordb.ClientOption{
+		Transport:   recorder,
+		Middlewares: []tcvectordb.Middleware{tcvectordb.RequestIDMiddleware("", func() string { return "request-id-1" })},
+	}
+	cli, err := tcvectordb.NewClient(httpSrv.URL, "root", "key-secret", &option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := createTestCollection(t, cli, tcvectordb.L2)
+	// the secret in the request body and in the response
+	query := &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(`author = "jerry"`)}
+	if _, err = coll.Query(ctx, nil, query); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sent.Get("Authorization"), "key-secret") || sent.Get(tcvectordb.RequestIDHeader) != "request-id-1" {
+		t.Fatalf("unexpected headers sent %v", sent)
+	}
+	// the secret in the grpc error message
+	rpcCli, err := rpcSrv.NewClient("root", "key-secret", &tcvectordb.ClientOption{GrpcDialOptions: recorder.DialOptions()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rpcCli.Database("db").Collection("jerry").Query(ctx, []string{"0001"}); err == nil {
+		t.Fatal("expect the query of the missing collection failed")
+	}
+	rpcCli.Close()
+	cli.Close()
+	rpcSrv.Close()
+	httpSrv.Close()
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"Bearer", "Authorization", "request-id-1", "key-secret", "jerry"} {
+		if strings.Contains(string(data), leaked) {
+			t.Fatalf("%s is recorded: %s", leaked, data)
+		}
+	}
+	if !strings.Contains(string(data), "collection db.****** not exist") {
+		t.Fatalf("the grpc error is not recorded scrubbed: %s", data)
+	}
+
+	// the replayed requests are scrubbed the same way, so they match whatever the headers are
+	player, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.Secrets = secrets
+	cli, err = tcvectordb.NewClient("http://127.0.0.1:1", "other", "other-key", &tcvectordb.ClientOption{Transport: player})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	res, err := cli.Database("db").Collection("coll").Query(ctx, nil, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 1 || res.Documents[0].Fields["author"].String() != "******" {
+		t.Fatalf("unexpected replayed result %+v", res.Documents)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
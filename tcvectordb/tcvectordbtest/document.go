@@ -0,0 +1,688 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordbtest
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/document"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/filter"
+)
+
+const defaultLimit = 10
+
+type sparseItem struct {
+	term  int64
+	score float32
+}
+
+type doc struct {
+	id     string
+	vector []float32
+	sparse []sparseItem
+	fields map[string]interface{}
+	// vectors holds the vector index fields other than the vector field
+	vectors map[string][]float32
+}
+
+// vectorOf returns the vector of the vector index field
+func (d *doc) vectorOf(field string) []float32 {
+	if v, ok := d.vectors[field]; ok {
+		return v
+	}
+	return d.vector
+}
+
+// filterFields returns the fields for the filter evaluation, which includes the id
+func (d *doc) filterFields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(d.fields)+1)
+	for k, v := range d.fields {
+		fields[k] = v
+	}
+	fields["id"] = d.id
+	return fields
+}
+
+func (d *doc) toDocument(retrieveVector bool, outputFields []string) *document.Document {
+	out := &document.Document{Id: d.id, Fields: make(map[string]interface{})}
+	if retrieveVector {
+		out.Vector = d.vector
+		for k, v := range d.vectors {
+			out.Fields[k] = v
+		}
+		for _, item := range d.sparse {
+			out.SparseVector = append(out.SparseVector, []interface{}{item.term, item.score})
+		}
+	}
+	if len(outputFields) == 0 {
+		for k, v := range d.fields {
+			out.Fields[k] = v
+		}
+	} else {
+		for _, k := range outputFields {
+			if v, ok := d.fields[k]; ok {
+				out.Fields[k] = v
+			}
+		}
+	}
+	return out
+}
+
+// vectorIndex returns the index of the vector field, or the first vector index if there is none
+func (c *coll) vectorIndex() *api.IndexColumn {
+	var first *api.IndexColumn
+	for _, idx := range c.item.Indexes {
+		if idx.FieldType != "vector" {
+			continue
+		}
+		if idx.FieldName == "vector" {
+			return idx
+		}
+		if first == nil {
+			first = idx
+		}
+	}
+	return first
+}
+
+// expire removes the documents whose ttl time field is before now
+func (s *Store) expire(c *coll) {
+	ttl := c.item.TtlConfig
+	if ttl == nil || !ttl.Enable {
+		return
+	}
+	now, _ := filter.ToNumber(s.now().Unix())
+	for id, d := range c.docs {
+		if t, ok := filter.ToNumber(d.fields[ttl.TimeField]); ok && t.Cmp(now) <= 0 {
+			c.remove(id)
+		}
+	}
+}
+
+func (c *coll) remove(id string) {
+	delete(c.docs, id)
+	for i, v := range c.order {
+		if v == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// filter parses the condition and checks the fields are filter indexes
+func (c *coll) filter(cond string) (filter.Expr, error) {
+	expr, err := filter.Parse(cond)
+	if err != nil {
+		return filter.Expr{}, errorf(CodeInvalidParameter, "%v", err)
+	}
+	for _, name := range expr.Fields() {
+		idx := findIndex(c.item.Indexes, name)
+		if idx == nil || (idx.IndexType != "filter" && idx.IndexType != "primaryKey") {
+			return filter.Expr{}, errorf(CodeInvalidParameter, "field %s in filter is not a filter index", name)
+		}
+	}
+	return expr, nil
+}
+
+// match returns the live documents in the upsert order, which have the ids if any and match the filter
+func (c *coll) match(ids []string, cond string) ([]*doc, error) {
+	expr, err := c.filter(cond)
+	if err != nil {
+		return nil, err
+	}
+	candidates := c.order
+	if len(ids) != 0 {
+		candidates = ids
+	}
+	var docs []*doc
+	for _, id := range candidates {
+		d, ok := c.docs[id]
+		if !ok {
+			continue
+		}
+		if !expr.Eval(d.filterFields()) {
+			continue
+		}
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
+
+func (c *coll) toDoc(in *document.Document) (*doc, error) {
+	if in.Id == "" {
+		return nil, errorf(CodeInvalidParameter, "document id is empty")
+	}
+	d := &doc{id: in.Id, vector: in.Vector, fields: make(map[string]interface{})}
+	if vi := c.vectorIndex(); vi != nil && len(in.Vector) != int(vi.Dimension) {
+		return nil, errorf(CodeInvalidParameter, "document %s vector dimension %d is not %d", in.Id, len(in.Vector), vi.Dimension)
+	}
+	sparse, err := toSparse(in.SparseVector)
+	if err != nil {
+		return nil, err
+	}
+	d.sparse = sparse
+	for k, v := range in.Fields {
+		if vector, ok, err := c.fieldVector(k, v); err != nil {
+			return nil, err
+		} else if ok {
+			if d.vectors == nil {
+				d.vectors = make(map[string][]float32)
+			}
+			d.vectors[k] = vector
+			continue
+		}
+		if err := checkFieldType(c.item.Indexes, k, v); err != nil {
+			return nil, err
+		}
+		d.fields[k] = v
+	}
+	return d, nil
+}
+
+// fieldVector converts the value of a vector index field other than the vector field
+func (c *coll) fieldVector(name string, v interface{}) ([]float32, bool, error) {
+	idx := findIndex(c.item.Indexes, name)
+	if idx == nil || idx.FieldType != "vector" || idx == c.vectorIndex() {
+		return nil, false, nil
+	}
+	vector, err := toVector(v)
+	if err != nil || len(vector) != int(idx.Dimension) {
+		return nil, false, errorf(CodeInvalidParameter, "field %s must be a vector of dimension %d", name, idx.Dimension)
+	}
+	return vector, true, nil
+}
+
+func toSparse(in [][]interface{}) ([]sparseItem, error) {
+	var items []sparseItem
+	for _, pair := range in {
+		if len(pair) != 2 {
+			return nil, errorf(CodeInvalidParameter, "sparse vector item must be [termId, score]")
+		}
+		term, ok1 := filter.ToNumber(pair[0])
+		score, ok2 := filter.ToNumber(pair[1])
+		if !ok1 || !ok2 {
+			return nil, errorf(CodeInvalidParameter, "sparse vector item must be numbers")
+		}
+		t, _ := term.Int64()
+		f, _ := score.Float32()
+		items = append(items, sparseItem{term: t, score: f})
+	}
+	return items, nil
+}
+
+// checkFieldType checks the values of the filter indexes
+func checkFieldType(indexes []*api.IndexColumn, name string, v interface{}) error {
+	idx := findIndex(indexes, name)
+	if idx == nil || idx.IndexType != "filter" {
+		return nil
+	}
+	ok := true
+	switch idx.FieldType {
+	case "string":
+		_, ok = v.(string)
+	case "uint64":
+		n, isNumber := v.(json.Number)
+		_, err := json.Number(n).Int64()
+		ok = isNumber && err == nil && n[0] != '-'
+	case "array":
+		_, ok = v.([]interface{})
+	}
+	if !ok {
+		return errorf(CodeInvalidParameter, "field %s must be %s", name, idx.FieldType)
+	}
+	return nil
+}
+
+func (s *Store) Upsert(req *document.UpsertReq) (*document.UpsertRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*doc, 0, len(req.Documents))
+	for _, in := range req.Documents {
+		d, err := c.toDoc(in)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	for _, d := range docs {
+		if _, ok := c.docs[d.id]; !ok {
+			c.order = append(c.order, d.id)
+		}
+		c.docs[d.id] = d
+	}
+	return &document.UpsertRes{AffectedCount: len(docs)}, nil
+}
+
+func (s *Store) Query(req *document.QueryReq) (*document.QueryRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	s.expire(c)
+	cond := req.Query
+	if cond == nil {
+		cond = new(document.QueryCond)
+	}
+	docs, err := c.match(cond.DocumentIds, cond.Filter)
+	if err != nil {
+		return nil, err
+	}
+	res := &document.QueryRes{Count: uint64(len(docs))}
+	if cond.Offset > int64(len(docs)) {
+		docs = nil
+	} else {
+		docs = docs[cond.Offset:]
+	}
+	if cond.Limit > 0 && int64(len(docs)) > cond.Limit {
+		docs = docs[:cond.Limit]
+	}
+	for _, d := range docs {
+		res.Documents = append(res.Documents, d.toDocument(cond.RetrieveVector, cond.OutputFields))
+	}
+	return res, nil
+}
+
+func (s *Store) Delete(req *document.DeleteReq) (*document.DeleteRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	s.expire(c)
+	if req.Query == nil || (len(req.Query.DocumentIds) == 0 && req.Query.Filter == "") {
+		return nil, errorf(CodeInvalidParameter, "documentIds or filter is required")
+	}
+	docs, err := c.match(req.Query.DocumentIds, req.Query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		c.remove(d.id)
+	}
+	return &document.DeleteRes{AffectedCount: len(docs)}, nil
+}
+
+func (s *Store) Update(req *document.UpdateReq) (*document.UpdateRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	s.expire(c)
+	if req.Query == nil || (len(req.Query.DocumentIds) == 0 && req.Query.Filter == "") {
+		return nil, errorf(CodeInvalidParameter, "documentIds or filter is required")
+	}
+	docs, err := c.match(req.Query.DocumentIds, req.Query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if vi := c.vectorIndex(); len(req.Update.Vector) != 0 && vi != nil && len(req.Update.Vector) != int(vi.Dimension) {
+		return nil, errorf(CodeInvalidParameter, "vector dimension %d is not %d", len(req.Update.Vector), vi.Dimension)
+	}
+	sparse, err := toSparse(req.Update.SparseVector)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make(map[string][]float32)
+	for k, v := range req.Update.Fields {
+		if vector, ok, err := c.fieldVector(k, v); err != nil {
+			return nil, err
+		} else if ok {
+			vectors[k] = vector
+			continue
+		}
+		if err := checkFieldType(c.item.Indexes, k, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range docs {
+		if len(req.Update.Vector) != 0 {
+			d.vector = req.Update.Vector
+		}
+		if len(sparse) != 0 {
+			d.sparse = sparse
+		}
+		for k, v := range req.Update.Fields {
+			if vector, ok := vectors[k]; ok {
+				if d.vectors == nil {
+					d.vectors = make(map[string][]float32)
+				}
+				d.vectors[k] = vector
+				continue
+			}
+			d.fields[k] = v
+		}
+	}
+	return &document.UpdateRes{AffectedCount: len(docs)}, nil
+}
+
+type scored struct {
+	doc   *doc
+	score float32
+}
+
+// annSearch returns the nearest documents of the vector by brute force.
+// L2 is the squared euclidean distance in ascending order, IP and COSINE are similarities in descending order.
+func annSearch(docs []*doc, field, metric string, vector []float32, limit int) []scored {
+	results := make([]scored, 0, len(docs))
+	for _, d := range docs {
+		v := d.vectorOf(field)
+		if len(v) != len(vector) {
+			continue
+		}
+		results = append(results, scored{doc: d, score: distance(metric, vector, v)})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if metric == "L2" {
+			return results[i].score < results[j].score
+		}
+		return results[i].score > results[j].score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func distance(metric string, a, b []float32) float32 {
+	var dot, l2, na, nb float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		l2 += (x - y) * (x - y)
+		na += x * x
+		nb += y * y
+	}
+	switch metric {
+	case "L2":
+		return float32(l2)
+	case "COSINE":
+		if na == 0 || nb == 0 {
+			return 0
+		}
+		return float32(dot / math.Sqrt(na*nb))
+	}
+	return float32(dot)
+}
+
+// sparseSearch returns the documents by the inner product of the sparse vectors in descending order
+func sparseSearch(docs []*doc, query []sparseItem, limit int) []scored {
+	weights := make(map[int64]float32, len(query))
+	for _, item := range query {
+		weights[item.term] += item.score
+	}
+	var results []scored
+	for _, d := range docs {
+		var score float32
+		for _, item := range d.sparse {
+			score += weights[item.term] * item.score
+		}
+		if score > 0 {
+			results = append(results, scored{doc: d, score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func (s *Store) Search(req *document.SearchReq) (*document.SearchRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	s.expire(c)
+	cond := req.Search
+	if cond == nil {
+		return nil, errorf(CodeInvalidParameter, "search is empty")
+	}
+	if len(cond.Retrieves) != 0 || len(cond.EmbeddingItems) != 0 {
+		return nil, errorf(CodeInvalidParameter, "search by text is not supported by the fake server")
+	}
+	docs, err := c.match(nil, cond.Filter)
+	if err != nil {
+		return nil, err
+	}
+	vectors := cond.Vectors
+	for _, id := range cond.DocumentIds {
+		d, ok := c.docs[id]
+		if !ok {
+			return nil, errorf(CodeInvalidParameter, "document %s not exist", id)
+		}
+		vectors = append(vectors, d.vector)
+	}
+	if len(vectors) == 0 {
+		return nil, errorf(CodeInvalidParameter, "vectors or documentIds is required")
+	}
+	limit := int(cond.Limit)
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	vi := c.vectorIndex()
+	metric := vi.MetricType
+	res := new(document.SearchRes)
+	for _, v := range vectors {
+		if len(v) != int(vi.Dimension) {
+			return nil, errorf(CodeInvalidParameter, "vector dimension %d is not %d", len(v), vi.Dimension)
+		}
+		var out []*document.Document
+		for _, r := range annSearch(docs, vi.FieldName, metric, v, limit) {
+			if cond.Params != nil && cond.Params.Radius != 0 && !withinRadius(metric, r.score, cond.Params.Radius) {
+				continue
+			}
+			d := r.doc.toDocument(cond.RetrieveVector, cond.OutputFields)
+			d.Score = r.score
+			out = append(out, d)
+		}
+		res.Documents = append(res.Documents, out)
+	}
+	return res, nil
+}
+
+func withinRadius(metric string, score, radius float32) bool {
+	if metric == "L2" {
+		return score <= radius
+	}
+	return score >= radius
+}
+
+// HybridSearch runs each ann and match route, then fuses them by the rerank method, rrf by default.
+// The routes are batched, the n-th vector of every route belongs to the n-th result.
+func (s *Store) HybridSearch(req *document.HybridSearchReq) (*document.SearchRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	s.expire(c)
+	cond := req.Search
+	if cond == nil || len(cond.AnnParams)+len(cond.Match) == 0 {
+		return nil, errorf(CodeInvalidParameter, "ann or match is required")
+	}
+	docs, err := c.match(nil, cond.Filter)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultLimit
+	if cond.Limit != nil && *cond.Limit > 0 {
+		limit = *cond.Limit
+	}
+
+	type route struct {
+		field   string
+		results [][]scored
+	}
+	var routes []route
+	batch := -1
+	for _, ann := range cond.AnnParams {
+		idx := findIndex(c.item.Indexes, ann.FieldName)
+		if idx == nil || idx.FieldType != "vector" {
+			return nil, errorf(CodeInvalidParameter, "%s is not a vector field", ann.FieldName)
+		}
+		routeLimit := limit
+		if ann.Limit != nil && *ann.Limit > 0 {
+			routeLimit = *ann.Limit
+		}
+		r := route{field: ann.FieldName}
+		for _, data := range ann.Data {
+			vector, err := toVector(data)
+			if err != nil || len(vector) != int(idx.Dimension) {
+				return nil, errorf(CodeInvalidParameter, "ann data of %s must be vectors of dimension %d", ann.FieldName, idx.Dimension)
+			}
+			r.results = append(r.results, annSearch(docs, ann.FieldName, idx.MetricType, vector, routeLimit))
+		}
+		for _, id := range ann.DocumentIds {
+			d, ok := c.docs[id]
+			if !ok {
+				return nil, errorf(CodeInvalidParameter, "document %s not exist", id)
+			}
+			r.results = append(r.results, annSearch(docs, ann.FieldName, idx.MetricType, d.vectorOf(ann.FieldName), routeLimit))
+		}
+		routes = append(routes, r)
+	}
+	for _, match := range cond.Match {
+		idx := findIndex(c.item.Indexes, match.FieldName)
+		if idx == nil || idx.FieldType != "sparseVector" {
+			return nil, errorf(CodeInvalidParameter, "%s is not a sparse vector field", match.FieldName)
+		}
+		routeLimit := limit
+		if match.Limit > 0 {
+			routeLimit = match.Limit
+		}
+		r := route{field: match.FieldName}
+		for _, data := range match.Data {
+			query, err := toSparse(data)
+			if err != nil {
+				return nil, err
+			}
+			r.results = append(r.results, sparseSearch(docs, query, routeLimit))
+		}
+		routes = append(routes, r)
+	}
+	for _, r := range routes {
+		if batch == -1 {
+			batch = len(r.results)
+		} else if batch != len(r.results) {
+			return nil, errorf(CodeInvalidParameter, "the routes have different numbers of queries")
+		}
+	}
+	rerank := cond.Rerank
+	if rerank == nil {
+		rerank = &document.RerankOption{Method: "rrf"}
+	}
+
+	res := new(document.SearchRes)
+	for q := 0; q < batch; q++ {
+		var fused []scored
+		if len(routes) == 1 {
+			fused = routes[0].results[q]
+		} else {
+			scores := make(map[*doc]float32)
+			var order []*doc
+			for _, r := range routes {
+				weight, err := rerankWeight(rerank, r.field)
+				if err != nil {
+					return nil, err
+				}
+				for rank, item := range r.results[q] {
+					if _, ok := scores[item.doc]; !ok {
+						order = append(order, item.doc)
+					}
+					if rerank.Method == "rrf" {
+						k := rerank.RrfK
+						if k <= 0 {
+							k = 60
+						}
+						scores[item.doc] += 1 / float32(int(k)+rank+1)
+					} else {
+						scores[item.doc] += weight * item.score
+					}
+				}
+			}
+			for _, d := range order {
+				fused = append(fused, scored{doc: d, score: scores[d]})
+			}
+			sort.SliceStable(fused, func(i, j int) bool { return fused[i].score > fused[j].score })
+		}
+		if len(fused) > limit {
+			fused = fused[:limit]
+		}
+		out := make([]*document.Document, 0, len(fused))
+		for _, r := range fused {
+			d := r.doc.toDocument(cond.RetrieveVector, cond.OutputFields)
+			d.Score = r.score
+			out = append(out, d)
+		}
+		res.Documents = append(res.Documents, out)
+	}
+	return res, nil
+}
+
+func rerankWeight(rerank *document.RerankOption, field string) (float32, error) {
+	switch rerank.Method {
+	case "rrf":
+		return 1, nil
+	case "weighted":
+		if len(rerank.FieldList) != len(rerank.Weight) {
+			return 0, errorf(CodeInvalidParameter, "rerank fieldList and weight have different lengths")
+		}
+		for i, f := range rerank.FieldList {
+			if f == field {
+				return rerank.Weight[i], nil
+			}
+		}
+		return 0, errorf(CodeInvalidParameter, "rerank weight of %s is missing", field)
+	}
+	return 0, errorf(CodeInvalidParameter, "unsupported rerank method %q", rerank.Method)
+}
+
+func toVector(data interface{}) ([]float32, error) {
+	switch v := data.(type) {
+	case []float32:
+		return v, nil
+	case []interface{}:
+		vector := make([]float32, 0, len(v))
+		for _, x := range v {
+			n, ok := filter.ToNumber(x)
+			if !ok {
+				return nil, errorf(CodeInvalidParameter, "vector element must be number")
+			}
+			f, _ := n.Float32()
+			vector = append(vector, f)
+		}
+		return vector, nil
+	}
+	return nil, errorf(CodeInvalidParameter, "vector must be an array of numbers")
+}
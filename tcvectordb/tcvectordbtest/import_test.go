@@ -0,0 +1,321 @@
+package tcvectordbtest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+)
+
+func TestImportJSONLAndCSV(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cli, _ := newTestCollection(t, srv, tcvectordb.L2)
+	ctx := context.Background()
+	coll := cli.Database("db").Collection("coll")
+
+	jsonl := `{"doc_id": "1001", "vector": [0.1, 0.2], "author": "alice", "page": 1, "tags": ["x"]}
+
+{"doc_id": "1002", "vector": "0.3,0.4", "sparse_vector": [[3, 0.5]], "author": "bob", "page": 2}
+{"doc_id": "1003", "vector": [0.5, 0.6], "author": "carol", "page": 3, "skip": "dropped"}
+`
+	var checkpoints []int64
+	option := &tcvectordb.ImportOption{
+		Columns:            map[string]string{"doc_id": "id", "skip": "-"},
+		Validate:           true,
+		CheckpointInterval: 2,
+		OnCheckpoint:       func(offset int64) { checkpoints = append(checkpoints, offset) },
+	}
+	result, err := tcvectordb.ImportJSONL(ctx, coll, strings.NewReader(jsonl), option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Records != 3 || result.AffectedCount != 3 || len(checkpoints) != 2 || checkpoints[0] != 2 || checkpoints[1] != 3 {
+		t.Fatalf("unexpected import result %+v, checkpoints %v", result, checkpoints)
+	}
+	query, err := coll.Query(ctx, []string{"1002", "1003"}, &tcvectordb.QueryDocumentParams{RetrieveVector: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Total != 2 || len(query.Documents[0].SparseVector) != 1 || query.Documents[0].Vector[1] != 0.4 {
+		t.Fatalf("unexpected imported documents %+v", query.Documents)
+	}
+	if _, ok := query.Documents[1].Fields["skip"]; ok {
+		t.Fatal("the dropped column is imported")
+	}
+
+	csv := "id,vector,page,tags,author\n" +
+		"2001,0.1;0.2,10,a;b,dave\n" +
+		"2002,0.3;0.4,20,,erin\n" +
+		"2003,0.5;0.6;0.7,30,c,frank\n"
+	option = &tcvectordb.ImportOption{VectorDelimiter: ";", Validate: true, StartOffset: 1}
+	result, err = tcvectordb.ImportCSV(ctx, coll, strings.NewReader(csv), option)
+	if err == nil || !strings.Contains(err.Error(), "record 2") || !strings.Contains(err.Error(), "dimension") {
+		t.Fatalf("expect the dimension error of record 2, got %v", err)
+	}
+	if result.Offset != 2 || result.Records != 1 || result.AffectedCount != 1 {
+		t.Fatalf("unexpected import result %+v", result)
+	}
+	query, err = coll.Query(ctx, []string{"2001", "2002", "2003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Total != 1 || query.Documents[0].Id != "2002" || query.Documents[0].Fields["page"].Uint64() != 20 {
+		t.Fatalf("unexpected imported documents %+v", query.Documents)
+	}
+}
+
+func TestImportBatchFailure(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.L2)
+	ctx := context.Background()
+
+	// the vector of 1002 is rejected by the server, the import goes on with the other records
+	jsonl := `{"id": "1001", "vector": [0.1, 0.2]}
+{"id": "1002", "vector": [0.1, 0.2, 0.3]}
+{"id": "1003", "vector": [0.5, 0.6]}
+{"id": "1004", "vector": [0.7, 0.8]}
+`
+	var checkpoints []int64
+	result, err := tcvectordb.ImportJSONL(ctx, coll, strings.NewReader(jsonl), &tcvectordb.ImportOption{
+		BatchSize:          2,
+		CheckpointInterval: 2,
+		OnCheckpoint:       func(offset int64) { checkpoints = append(checkpoints, offset) },
+	})
+	if err == nil {
+		t.Fatal("expect the error of the failed batch")
+	}
+	if result.Records != 4 || result.AffectedCount != 3 || len(result.FailedIds) != 1 || result.FailedIds[0] != "1002" {
+		t.Fatalf("unexpected import result %+v", result)
+	}
+	// the failed record is before all the offsets, so none of them is a checkpoint
+	if len(checkpoints) != 0 {
+		t.Fatalf("unexpected checkpoints %v", checkpoints)
+	}
+	query, err := coll.Query(ctx, []string{"1001", "1003", "1004"})
+	if err != nil || query.Total != 3 {
+		t.Fatalf("unexpected imported documents %+v, %v", query, err)
+	}
+
+	// the parse error stops the import without a checkpoint of the records after the last flush
+	jsonl = `{"id": "2001", "vector": [0.1, 0.2]}
+{"id": "2002", "vector": [0.3, 0.4]}
+{"id": "2003", "vector": [0.5, 0.6]}
+not json
+`
+	checkpoints = nil
+	result, err = tcvectordb.ImportJSONL(ctx, coll, strings.NewReader(jsonl), &tcvectordb.ImportOption{
+		CheckpointInterval: 2,
+		OnCheckpoint:       func(offset int64) { checkpoints = append(checkpoints, offset) },
+	})
+	if err == nil || result.Offset != 3 || len(checkpoints) != 1 || checkpoints[0] != 2 {
+		t.Fatalf("unexpected import result %+v, checkpoints %v, %v", result, checkpoints, err)
+	}
+}
+
+func TestImportThrottle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.L2)
+
+	// the throttle slows down the import under a deadline rather than failing it
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var jsonl strings.Builder
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&jsonl, `{"id": "30%02d", "vector": [0.1, 0.2]}`+"\n", i)
+	}
+	start := time.Now()
+	result, err := tcvectordb.ImportJSONL(ctx, coll, strings.NewReader(jsonl.String()),
+		&tcvectordb.ImportOption{RecordsPerSecond: 20})
+	if err != nil || result.Records != 5 {
+		t.Fatalf("unexpected import result %+v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("the import is not throttled, elapsed %v", elapsed)
+	}
+}
+
+func TestExportAndRestore(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cli, coll := newTestCollection(t, srv, tcvectordb.COSINE)
+	ctx := context.Background()
+
+	buf := new(strings.Builder)
+	exported, err := tcvectordb.ExportCollection(ctx, coll, buf, &tcvectordb.ExportOption{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if exported.Documents != 3 || len(lines) != 4 || !strings.HasPrefix(lines[0], `{"header":{"format":"tcvectordb-export/v1"`) {
+		t.Fatalf("unexpected export %d documents:\n%s", exported.Documents, buf.String())
+	}
+
+	db := cli.Database("db")
+	restored, err := tcvectordb.RestoreCollection(ctx, db, strings.NewReader(buf.String()), &tcvectordb.RestoreOption{Collection: "copy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Records != 3 || restored.AffectedCount != 3 {
+		t.Fatalf("unexpected restore result %+v", restored)
+	}
+	described, err := db.DescribeCollection(ctx, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if described.Indexes.VectorIndex[0].MetricType != tcvectordb.COSINE || len(described.Indexes.FilterIndex) != 5 ||
+		len(described.Indexes.SparseVectorIndex) != 1 || described.TtlConfig == nil || described.TtlConfig.TimeField != "expire_at" {
+		t.Fatalf("unexpected restored collection %+v", described)
+	}
+	query, err := db.Collection("copy").Query(ctx, []string{"0001"}, &tcvectordb.QueryDocumentParams{RetrieveVector: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := query.Documents[0]
+	if len(doc.Vector) != 2 || len(doc.SparseVector) != 1 || doc.Fields["page"].Uint64() != 10 || len(doc.Fields["tags"].StringArray()) != 2 {
+		t.Fatalf("unexpected restored document %+v", doc)
+	}
+
+	if _, err = tcvectordb.RestoreCollection(ctx, db, strings.NewReader(buf.String()), &tcvectordb.RestoreOption{Collection: "copy"}); err == nil {
+		t.Fatal("expect the existing collection is not overwritten")
+	}
+	if _, err = tcvectordb.RestoreCollection(ctx, db, strings.NewReader(buf.String()),
+		&tcvectordb.RestoreOption{Collection: "copy", DropExisting: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestExportEncodeError(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.L2)
+
+	if _, err := tcvectordb.ExportCollection(context.Background(), coll, failingWriter{}, nil); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expect the write error returned, got %v", err)
+	}
+}
+
+func TestRestoreHeaderMismatch(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cli, coll := newTestCollection(t, srv, tcvectordb.L2)
+	ctx := context.Background()
+	db := cli.Database("db")
+
+	buf := new(strings.Builder)
+	if _, err := tcvectordb.ExportCollection(ctx, coll, buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	exported := buf.String()
+	lines := strings.SplitN(exported, "\n", 2)
+	for name, input := range map[string]string{
+		"other format":   strings.Replace(exported, tcvectordb.ExportFormat, "tcvectordb-export/v2", 1),
+		"missing header": lines[1],
+		"empty input":    "",
+	} {
+		if _, err := tcvectordb.ReadExportHeader(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Fatalf("%s: expect the header rejected", name)
+		}
+		if _, err := tcvectordb.RestoreCollection(ctx, db, strings.NewReader(input), &tcvectordb.RestoreOption{Collection: "copy"}); err == nil {
+			t.Fatalf("%s: expect the restore rejected", name)
+		}
+		if exists, err := db.ExistsCollection(ctx, "copy"); err != nil || exists {
+			t.Fatalf("%s: the collection is created without a valid header, %v", name, err)
+		}
+	}
+
+	// the documents do not fit the existing collection of another dimension
+	if _, err := db.CreateCollection(ctx, "other", 1, 1, "", tcvectordb.Indexes{
+		VectorIndex: []tcvectordb.VectorIndex{{
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   3,
+			MetricType:  tcvectordb.L2,
+		}},
+		FilterIndex: []tcvectordb.FilterIndex{{FieldName: "id", FieldType: tcvectordb.String, IndexType: tcvectordb.PRIMARY}},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := tcvectordb.RestoreCollection(ctx, db, strings.NewReader(exported),
+		&tcvectordb.RestoreOption{Collection: "other", SkipCreate: true})
+	if err == nil || restored == nil || len(restored.FailedIds) != 3 || restored.AffectedCount != 0 {
+		t.Fatalf("expect all the documents failed, got %+v, %v", restored, err)
+	}
+}
+
+func TestRestoreEmbeddingCollection(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cli, err := tcvectordb.NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err = cli.CreateDatabase(ctx, "db"); err != nil {
+		t.Fatal(err)
+	}
+	db := cli.Database("db")
+	embedding := &tcvectordb.Embedding{Field: "text", VectorField: "vector", Model: tcvectordb.BGE_BASE_ZH}
+	coll, err := db.CreateCollection(ctx, "coll", 1, 1, "", tcvectordb.Indexes{
+		VectorIndex: []tcvectordb.VectorIndex{{
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   768,
+			MetricType:  tcvectordb.COSINE,
+		}},
+		FilterIndex: []tcvectordb.FilterIndex{{FieldName: "id", FieldType: tcvectordb.String, IndexType: tcvectordb.PRIMARY}},
+	}, &tcvectordb.CreateCollectionParams{Embedding: embedding})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fake does not embed, the vectors stand for the ones made by the server
+	vector := make([]float32, 768)
+	vector[0] = 1
+	if _, err = coll.Upsert(ctx, []tcvectordb.Document{
+		{Id: "0001", Vector: vector, Fields: map[string]tcvectordb.Field{"text": {Val: "hello"}}},
+		{Id: "0002", Vector: vector, Fields: map[string]tcvectordb.Field{"text": {Val: "world"}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(strings.Builder)
+	exported, err := tcvectordb.ExportCollection(ctx, coll, buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported.Header.Embedding == nil || exported.Header.Embedding.Field != "text" {
+		t.Fatalf("the embedding is not exported: %+v", exported.Header)
+	}
+	restored, err := tcvectordb.RestoreCollection(ctx, db, strings.NewReader(buf.String()), &tcvectordb.RestoreOption{Collection: "copy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.AffectedCount != 2 {
+		t.Fatalf("unexpected restore result %+v", restored)
+	}
+	described, err := db.DescribeCollection(ctx, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if described.Embedding.Field != "text" || described.Embedding.VectorField != "vector" || described.Embedding.Model != tcvectordb.BGE_BASE_ZH {
+		t.Fatalf("unexpected restored embedding %+v", described.Embedding)
+	}
+	query, err := db.Collection("copy").Query(ctx, []string{"0002"}, &tcvectordb.QueryDocumentParams{RetrieveVector: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Total != 1 || query.Documents[0].Fields["text"].String() != "world" || len(query.Documents[0].Vector) != 768 {
+		t.Fatalf("unexpected restored document %+v", query.Documents)
+	}
+}
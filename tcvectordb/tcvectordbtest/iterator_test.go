@@ -0,0 +1,152 @@
+package tcvectordbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+)
+
+func TestCollectionIterate(t *testing.T) {
+	httpSrv := NewServer()
+	defer httpSrv.Close()
+	rpcSrv := NewRpcServer()
+	defer rpcSrv.Close()
+	rpcCli, err := rpcSrv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcCli.Close()
+	httpCli, _ := newTestCollection(t, httpSrv, tcvectordb.L2)
+	createTestCollection(t, rpcCli, tcvectordb.L2)
+	ctx := context.Background()
+
+	for name, coll := range map[string]*tcvectordb.Collection{
+		"http": httpCli.Database("db").Collection("coll"),
+		"grpc": rpcCli.Database("db").Collection("coll"),
+	} {
+		var docs []tcvectordb.Document
+		for i := 0; i < 20; i++ {
+			docs = append(docs, tcvectordb.Document{Id: fmt.Sprintf("%04d", 100+i), Vector: []float32{1, 1},
+				Fields: map[string]tcvectordb.Field{"page": {Val: 100 + i}}})
+		}
+		if _, err := coll.Upsert(ctx, docs); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, prefetch := range []bool{true, false} {
+			it := coll.Iterate(ctx, &tcvectordb.IterateParams{
+				Filter: tcvectordb.NewFilter(`page >= 100`), PageSize: 4, DisablePrefetch: !prefetch,
+			})
+			seen := make(map[string]int)
+			var order []string
+			for it.Next() {
+				seen[it.Document().Id]++
+				order = append(order, it.Document().Id)
+				if len(seen) == 6 {
+					// delete the seen documents and insert a new one during the iteration
+					if _, err := coll.Delete(ctx, tcvectordb.DeleteDocumentParams{DocumentIds: order[:3]}); err != nil {
+						t.Fatal(err)
+					}
+					if _, err := coll.Upsert(ctx, []tcvectordb.Document{{Id: "0200", Vector: []float32{1, 1},
+						Fields: map[string]tcvectordb.Field{"page": {Val: 200}}}}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			it.Close()
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			for id, n := range seen {
+				if n != 1 {
+					t.Fatalf("%s: the document %s is iterated %d times", name, id, n)
+				}
+			}
+			if len(seen) != 21 || seen["0119"] != 1 || seen["0200"] != 1 {
+				t.Fatalf("%s: expect 21 documents iterated, got %d", name, len(seen))
+			}
+			if _, err := coll.Upsert(ctx, docs); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := coll.Delete(ctx, tcvectordb.DeleteDocumentParams{DocumentIds: []string{"0200"}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		it := coll.Iterate(ctx, &tcvectordb.IterateParams{Filter: tcvectordb.NewFilter(`page >= 100`), PageSize: 4, Limit: 5})
+		count := 0
+		for it.Next() {
+			count++
+		}
+		it.Close()
+		if count != 5 || it.Err() != nil {
+			t.Fatalf("%s: expect 5 documents by the limit, got %d, %v", name, count, it.Err())
+		}
+
+		it = coll.Iterate(ctx, &tcvectordb.IterateParams{PageSize: 2})
+		if !it.Next() {
+			t.Fatalf("%s: expect a document, got %v", name, it.Err())
+		}
+		it.Close()
+	}
+}
+
+func TestCollectionIterateDeleted(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.L2)
+	ctx := context.Background()
+
+	var docs []tcvectordb.Document
+	for i := 0; i < 60; i++ {
+		docs = append(docs, tcvectordb.Document{Id: fmt.Sprintf("%04d", 100+i), Vector: []float32{1, 1},
+			Fields: map[string]tcvectordb.Field{"page": {Val: 100 + i}}})
+	}
+	if _, err := coll.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// the last document seen is deleted, the iteration continues after the one before it
+	it := coll.Iterate(ctx, &tcvectordb.IterateParams{PageSize: 4, DisablePrefetch: true})
+	seen := make(map[string]int)
+	for it.Next() {
+		seen[it.Document().Id]++
+		if len(seen) == 8 {
+			if _, err := coll.Delete(ctx, tcvectordb.DeleteDocumentParams{DocumentIds: []string{it.Document().Id}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	it.Close()
+	// with the 3 documents of newTestCollection
+	if err := it.Err(); err != nil || len(seen) != 63 {
+		t.Fatalf("expect 63 documents iterated, got %d, %v", len(seen), err)
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Fatalf("the document %s is iterated %d times", id, n)
+		}
+	}
+	if _, err := coll.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// all the documents seen are deleted, which are too many to search backward
+	it = coll.Iterate(ctx, &tcvectordb.IterateParams{PageSize: 2, DisablePrefetch: true})
+	var order []string
+	for it.Next() {
+		order = append(order, it.Document().Id)
+		if len(order) == 50 {
+			if _, err := coll.Delete(ctx, tcvectordb.DeleteDocumentParams{DocumentIds: order}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	it.Close()
+	if !errors.Is(it.Err(), tcvectordb.ErrIteratorPositionLost) {
+		t.Fatalf("expect ErrIteratorPositionLost after %d documents, got %v", len(order), it.Err())
+	}
+}
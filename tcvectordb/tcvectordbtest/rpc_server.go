@@ -0,0 +1,597 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordbtest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/alias"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/collection"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/database"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/document"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/index"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/filter"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/olama"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// RpcServer is the fake olama.SearchEngineServer served over bufconn.
+// Like the vectordb server, the failures are returned as the code and msg of the responses,
+// and the requests without authorization fail with codes.Unauthenticated.
+type RpcServer struct {
+	olama.UnimplementedSearchEngineServer
+	*Store
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+// NewRpcServer start a fake grpc server with an empty store
+func NewRpcServer() *RpcServer {
+	return NewRpcServerWithStore(NewStore())
+}
+
+// NewRpcServerWithStore start a fake grpc server on the store, which could be shared with the http fake server
+func NewRpcServerWithStore(store *Store) *RpcServer {
+	s := &RpcServer{Store: store, listener: bufconn.Listen(1024 * 1024)}
+	s.server = grpc.NewServer(grpc.UnaryInterceptor(authInterceptor))
+	for _, desc := range rpcServiceDescs() {
+		s.server.RegisterService(desc, s)
+	}
+	go s.server.Serve(s.listener)
+	return s
+}
+
+// rpcRoutes maps the methods of olama.SearchEngine_ServiceDesc to the full method names called by the sdk
+var rpcRoutes = map[string]string{
+	"setAlias":           olama.SearchEngine_SetAlias_FullMethodName,
+	"getAlias":           olama.SearchEngine_GetAlias_FullMethodName,
+	"deleteAlias":        olama.SearchEngine_DeleteAlias_FullMethodName,
+	"createCollection":   olama.SearchEngine_CreateCollection_FullMethodName,
+	"dropCollection":     olama.SearchEngine_DropCollection_FullMethodName,
+	"truncateCollection": olama.SearchEngine_TruncateCollection_FullMethodName,
+	"describeCollection": olama.SearchEngine_DescribeCollection_FullMethodName,
+	"listCollections":    olama.SearchEngine_ListCollections_FullMethodName,
+	"rebuildIndex":       olama.SearchEngine_RebuildIndex_FullMethodName,
+	"upsert":             olama.SearchEngine_Upsert_FullMethodName,
+	"update":             olama.SearchEngine_Update_FullMethodName,
+	"query":              olama.SearchEngine_Query_FullMethodName,
+	"search":             olama.SearchEngine_Search_FullMethodName,
+	"hybrid_search":      olama.SearchEngine_HybridSearch_FullMethodName,
+	"dele":               olama.SearchEngine_Dele_FullMethodName,
+	"createDatabase":     olama.SearchEngine_CreateDatabase_FullMethodName,
+	"dropDatabase":       olama.SearchEngine_DropDatabase_FullMethodName,
+	"listDatabases":      olama.SearchEngine_ListDatabases_FullMethodName,
+	"get_version":        olama.SearchEngine_GetVersion_FullMethodName,
+}
+
+// rpcServiceDescs splits olama.SearchEngine_ServiceDesc by the full method names,
+// e.g. "/document/upsert" is served as the method upsert of the service document.
+func rpcServiceDescs() []*grpc.ServiceDesc {
+	descs := make(map[string]*grpc.ServiceDesc)
+	var names []string
+	for _, method := range olama.SearchEngine_ServiceDesc.Methods {
+		route := strings.SplitN(strings.TrimPrefix(rpcRoutes[method.MethodName], "/"), "/", 2)
+		if len(route) != 2 {
+			continue
+		}
+		desc, ok := descs[route[0]]
+		if !ok {
+			desc = &grpc.ServiceDesc{ServiceName: route[0], HandlerType: olama.SearchEngine_ServiceDesc.HandlerType}
+			descs[route[0]] = desc
+			names = append(names, route[0])
+		}
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{MethodName: route[1], Handler: method.Handler})
+	}
+	out := make([]*grpc.ServiceDesc, 0, len(names))
+	for _, name := range names {
+		out = append(out, descs[name])
+	}
+	return out
+}
+
+func authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	auth := md.Get("authorization")
+	if len(auth) == 0 || !strings.HasPrefix(auth[0], "Bearer account=") {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return handler(ctx, req)
+}
+
+// DialOptions returns the grpc dial options which connect to the server over bufconn
+func (s *RpcServer) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+}
+
+// NewClient new a RpcClient connected to the server. The http fallback of the client,
+// which is used by the ai database apis, is not served.
+func (s *RpcServer) NewClient(username, key string, option *tcvectordb.ClientOption) (*tcvectordb.RpcClient, error) {
+	opt := tcvectordb.ClientOption{}
+	if option != nil {
+		opt = *option
+	}
+	opt.GrpcDialOptions = append(s.DialOptions(), opt.GrpcDialOptions...)
+	return tcvectordb.NewRpcClient("passthrough:///bufnet", username, key, &opt)
+}
+
+// Close stop the server
+func (s *RpcServer) Close() {
+	s.server.Stop()
+	s.listener.Close()
+}
+
+// rpcError returns the code and msg of the store error for the response
+func rpcError(err error) (int32, string, error) {
+	if e, ok := err.(*Error); ok {
+		return e.Code, e.Msg, nil
+	}
+	return 0, "", status.Error(codes.Internal, err.Error())
+}
+
+func (s *RpcServer) GetVersion(ctx context.Context, req *olama.GetVersionRequest) (*olama.GetVersionResponse, error) {
+	timestamp, kernalVersion := s.Store.GetVersion()
+	return &olama.GetVersionResponse{Timestamp: timestamp, KernalVersion: kernalVersion}, nil
+}
+
+func (s *RpcServer) CreateDatabase(ctx context.Context, req *olama.DatabaseRequest) (*olama.DatabaseResponse, error) {
+	res, err := s.Store.CreateDatabase(&database.CreateReq{Database: req.Database})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.DatabaseResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.DatabaseResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) DropDatabase(ctx context.Context, req *olama.DatabaseRequest) (*olama.DatabaseResponse, error) {
+	res, err := s.Store.DropDatabase(&database.DropReq{Database: req.Database})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.DatabaseResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.DatabaseResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) ListDatabases(ctx context.Context, req *olama.DatabaseRequest) (*olama.DatabaseResponse, error) {
+	res, _ := s.Store.ListDatabase(&database.ListReq{})
+	out := &olama.DatabaseResponse{Databases: res.Databases, Info: make(map[string]*olama.DatabaseItem)}
+	for name, info := range res.Info {
+		created, _ := time.ParseInLocation(timeLayout, info.CreateTime, time.Local)
+		out.Info[name] = &olama.DatabaseItem{CreateTime: created.Unix(), DbType: olama.DataType_BASE}
+	}
+	return out, nil
+}
+
+func (s *RpcServer) CreateCollection(ctx context.Context, req *olama.CreateCollectionRequest) (*olama.CreateCollectionResponse, error) {
+	in := &collection.CreateReq{
+		Database:    req.Database,
+		Collection:  req.Collection,
+		ReplicaNum:  req.ReplicaNum,
+		ShardNum:    req.ShardNum,
+		Description: req.Description,
+	}
+	names := make([]string, 0, len(req.Indexes))
+	for name := range req.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		idx := req.Indexes[name]
+		column := &api.IndexColumn{
+			FieldName:        idx.FieldName,
+			FieldType:        idx.FieldType,
+			FieldElementType: idx.FieldElementType,
+			IndexType:        idx.IndexType,
+			Dimension:        idx.Dimension,
+			MetricType:       idx.MetricType,
+		}
+		if idx.Params != nil {
+			column.Params = &api.IndexParams{M: idx.Params.M, EfConstruction: idx.Params.EfConstruction,
+				Nprobe: idx.Params.Nprobe, Nlist: idx.Params.Nlist}
+		}
+		in.Indexes = append(in.Indexes, column)
+	}
+	if req.EmbeddingParams != nil {
+		in.Embedding = collection.Embedding{Field: req.EmbeddingParams.Field,
+			VectorField: req.EmbeddingParams.VectorField, Model: req.EmbeddingParams.ModelName}
+	}
+	if req.TtlConfig != nil {
+		in.TtlConfig = &collection.TtlConfig{Enable: req.TtlConfig.Enable, TimeField: req.TtlConfig.TimeField}
+	}
+	res, err := s.Store.CreateCollection(in)
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.CreateCollectionResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.CreateCollectionResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func toRpcCollection(item *collection.DescribeCollectionItem) *olama.CreateCollectionRequest {
+	out := &olama.CreateCollectionRequest{
+		Database:    item.Database,
+		Collection:  item.Collection,
+		ReplicaNum:  item.ReplicaNum,
+		ShardNum:    item.ShardNum,
+		Size:        uint64(item.DocumentCount),
+		CreateTime:  item.CreateTime,
+		Description: item.Description,
+		Indexes:     make(map[string]*olama.IndexColumn),
+		AliasList:   item.Alias,
+	}
+	for _, idx := range item.Indexes {
+		column := &olama.IndexColumn{
+			FieldName:        idx.FieldName,
+			FieldType:        idx.FieldType,
+			FieldElementType: idx.FieldElementType,
+			IndexType:        idx.IndexType,
+			Dimension:        idx.Dimension,
+			MetricType:       idx.MetricType,
+		}
+		if idx.Params != nil {
+			column.Params = &olama.IndexParams{M: idx.Params.M, EfConstruction: idx.Params.EfConstruction,
+				Nprobe: idx.Params.Nprobe, Nlist: idx.Params.Nlist}
+		}
+		out.Indexes[idx.FieldName] = column
+	}
+	if item.IndexStatus != nil {
+		out.IndexStatus = &olama.IndexStatus{Status: item.IndexStatus.Status, Progress: item.IndexStatus.Progress,
+			StartTime: item.IndexStatus.StartTime}
+	}
+	if item.Embedding != nil {
+		out.EmbeddingParams = &olama.EmbeddingParams{Field: item.Embedding.Field,
+			VectorField: item.Embedding.VectorField, ModelName: item.Embedding.Model}
+	}
+	if item.TtlConfig != nil {
+		out.TtlConfig = &olama.TTLConfig{Enable: item.TtlConfig.Enable, TimeField: item.TtlConfig.TimeField}
+	}
+	return out
+}
+
+func (s *RpcServer) DescribeCollection(ctx context.Context, req *olama.DescribeCollectionRequest) (*olama.DescribeCollectionResponse, error) {
+	res, err := s.Store.DescribeCollection(&collection.DescribeReq{Database: req.Database, Collection: req.Collection})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.DescribeCollectionResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.DescribeCollectionResponse{Collection: toRpcCollection(res.Collection)}, nil
+}
+
+func (s *RpcServer) ListCollections(ctx context.Context, req *olama.ListCollectionsRequest) (*olama.ListCollectionsResponse, error) {
+	res, err := s.Store.ListCollection(&collection.ListReq{Database: req.Database})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.ListCollectionsResponse{Code: code, Msg: msg}, err
+	}
+	out := new(olama.ListCollectionsResponse)
+	for _, item := range res.Collections {
+		out.Collections = append(out.Collections, toRpcCollection(item))
+	}
+	return out, nil
+}
+
+func (s *RpcServer) DropCollection(ctx context.Context, req *olama.DropCollectionRequest) (*olama.DropCollectionResponse, error) {
+	res, err := s.Store.DropCollection(&collection.DropReq{Database: req.Database, Collection: req.Collection,
+		Force: req.Force, WithoutAlias: req.WithoutAlias})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.DropCollectionResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.DropCollectionResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) TruncateCollection(ctx context.Context, req *olama.TruncateCollectionRequest) (*olama.TruncateCollectionResponse, error) {
+	res, err := s.Store.TruncateCollection(&collection.TruncateReq{Database: req.Database, Collection: req.Collection,
+		OnlyFlushAnnIndex: req.OnlyTruncateAnnIndex})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.TruncateCollectionResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.TruncateCollectionResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) RebuildIndex(ctx context.Context, req *olama.RebuildIndexRequest) (*olama.RebuildIndexResponse, error) {
+	res, err := s.Store.RebuildIndex(&index.RebuildReq{Database: req.Database, Collection: req.Collection,
+		DropBeforeRebuild: req.DropBeforeRebuild, Throttle: req.Throttle})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.RebuildIndexResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.RebuildIndexResponse{TaskIds: res.TaskIds}, nil
+}
+
+func (s *RpcServer) SetAlias(ctx context.Context, req *olama.AddAliasRequest) (*olama.UpdateAliasResponse, error) {
+	res, err := s.Store.SetAlias(&alias.SetReq{Database: req.Database, Collection: req.Collection, Alias: req.Alias})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.UpdateAliasResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.UpdateAliasResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) DeleteAlias(ctx context.Context, req *olama.RemoveAliasRequest) (*olama.UpdateAliasResponse, error) {
+	res, err := s.Store.DeleteAlias(&alias.DeleteReq{Database: req.Database, Alias: req.Alias})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.UpdateAliasResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.UpdateAliasResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+// GetAlias describes the alias, or lists all aliases of the database if the alias is empty
+func (s *RpcServer) GetAlias(ctx context.Context, req *olama.GetAliasRequest) (*olama.GetAliasResponse, error) {
+	var items []*alias.AliasItem
+	var err error
+	if req.Alias == "" {
+		var res *alias.ListRes
+		if res, err = s.Store.ListAlias(&alias.ListReq{Database: req.Database}); err == nil {
+			items = res.Aliases
+		}
+	} else {
+		var res *alias.DescribeRes
+		if res, err = s.Store.DescribeAlias(&alias.DescribeReq{Database: req.Database, Alias: req.Alias}); err == nil {
+			items = res.Aliases
+		}
+	}
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.GetAliasResponse{Code: code, Msg: msg}, err
+	}
+	out := new(olama.GetAliasResponse)
+	for _, item := range items {
+		out.Aliases = append(out.Aliases, &olama.AliasItem{Alias: item.Alias, Collection: item.Collection})
+	}
+	return out, nil
+}
+
+func (s *RpcServer) Upsert(ctx context.Context, req *olama.UpsertRequest) (*olama.UpsertResponse, error) {
+	in := &document.UpsertReq{Database: req.Database, Collection: req.Collection}
+	for _, d := range req.Documents {
+		in.Documents = append(in.Documents, fromRpcDocument(d))
+	}
+	res, err := s.Store.Upsert(in)
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.UpsertResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.UpsertResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) Query(ctx context.Context, req *olama.QueryRequest) (*olama.QueryResponse, error) {
+	res, err := s.Store.Query(&document.QueryReq{Database: req.Database, Collection: req.Collection,
+		Query: fromRpcQueryCond(req.Query), ReadConsistency: req.ReadConsistency})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.QueryResponse{Code: code, Msg: msg}, err
+	}
+	out := &olama.QueryResponse{Count: res.Count}
+	for _, d := range res.Documents {
+		out.Documents = append(out.Documents, toRpcDocument(d))
+	}
+	return out, nil
+}
+
+func (s *RpcServer) Dele(ctx context.Context, req *olama.DeleteRequest) (*olama.DeleteResponse, error) {
+	res, err := s.Store.Delete(&document.DeleteReq{Database: req.Database, Collection: req.Collection,
+		Query: fromRpcQueryCond(req.Query)})
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.DeleteResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.DeleteResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) Update(ctx context.Context, req *olama.UpdateRequest) (*olama.UpdateResponse, error) {
+	in := &document.UpdateReq{Database: req.Database, Collection: req.Collection, Query: fromRpcQueryCond(req.Query)}
+	if req.Update != nil {
+		in.Update = *fromRpcDocument(req.Update)
+	}
+	res, err := s.Store.Update(in)
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.UpdateResponse{Code: code, Msg: msg}, err
+	}
+	return &olama.UpdateResponse{AffectedCount: uint64(res.AffectedCount)}, nil
+}
+
+func (s *RpcServer) Search(ctx context.Context, req *olama.SearchRequest) (*olama.SearchResponse, error) {
+	cond := req.GetSearch()
+	in := &document.SearchReq{Database: req.Database, Collection: req.Collection, ReadConsistency: req.ReadConsistency,
+		Search: &document.SearchCond{
+			DocumentIds:    cond.GetDocumentIds(),
+			RetrieveVector: cond.GetRetrieveVector(),
+			Limit:          int64(cond.GetLimit()),
+			OutputFields:   cond.GetOutputfields(),
+			Filter:         cond.GetFilter(),
+			EmbeddingItems: cond.GetEmbeddingItems(),
+		}}
+	if p := cond.GetParams(); p != nil {
+		in.Search.Params = &document.SearchParams{Nprobe: p.Nprobe, Ef: p.Ef, Radius: p.Radius}
+	}
+	for _, v := range cond.GetVectors() {
+		in.Search.Vectors = append(in.Search.Vectors, v.Vector)
+	}
+	res, err := s.Store.Search(in)
+	return toRpcSearchResponse(res, err)
+}
+
+func (s *RpcServer) HybridSearch(ctx context.Context, req *olama.SearchRequest) (*olama.SearchResponse, error) {
+	cond := req.GetSearch()
+	in := &document.HybridSearchReq{Database: req.Database, Collection: req.Collection, ReadConsistency: req.ReadConsistency,
+		Search: &document.HybridSearchCond{
+			RetrieveVector: cond.GetRetrieveVector(),
+			OutputFields:   cond.GetOutputfields(),
+			Filter:         cond.GetFilter(),
+		}}
+	if cond.GetLimit() != 0 {
+		limit := int(cond.GetLimit())
+		in.Search.Limit = &limit
+	}
+	for _, ann := range cond.GetAnn() {
+		param := &document.AnnParam{FieldName: ann.FieldName, DocumentIds: ann.DocumentIds}
+		for _, v := range ann.Data {
+			param.Data = append(param.Data, v.Vector)
+		}
+		if ann.Limit != 0 {
+			limit := int(ann.Limit)
+			param.Limit = &limit
+		}
+		in.Search.AnnParams = append(in.Search.AnnParams, param)
+	}
+	for _, sparse := range cond.GetSparse() {
+		match := &document.MatchOption{FieldName: sparse.FieldName, Limit: int(sparse.Limit)}
+		for _, v := range sparse.Data {
+			match.Data = append(match.Data, fromRpcSparse(v.SpVector))
+		}
+		in.Search.Match = append(in.Search.Match, match)
+	}
+	if rerank := cond.GetRerankParams(); rerank != nil {
+		in.Search.Rerank = &document.RerankOption{Method: rerank.Method, RrfK: rerank.RrfK}
+		fields := make([]string, 0, len(rerank.Weights))
+		for field := range rerank.Weights {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			in.Search.Rerank.FieldList = append(in.Search.Rerank.FieldList, field)
+			in.Search.Rerank.Weight = append(in.Search.Rerank.Weight, rerank.Weights[field])
+		}
+	}
+	res, err := s.Store.HybridSearch(in)
+	return toRpcSearchResponse(res, err)
+}
+
+func toRpcSearchResponse(res *document.SearchRes, err error) (*olama.SearchResponse, error) {
+	if err != nil {
+		code, msg, err := rpcError(err)
+		return &olama.SearchResponse{Code: code, Msg: msg}, err
+	}
+	out := new(olama.SearchResponse)
+	for _, docs := range res.Documents {
+		result := new(olama.SearchResult)
+		for _, d := range docs {
+			result.Documents = append(result.Documents, toRpcDocument(d))
+		}
+		out.Results = append(out.Results, result)
+	}
+	return out, nil
+}
+
+func fromRpcQueryCond(cond *olama.QueryCond) *document.QueryCond {
+	if cond == nil {
+		return nil
+	}
+	return &document.QueryCond{
+		DocumentIds:    cond.DocumentIds,
+		RetrieveVector: cond.RetrieveVector,
+		Filter:         cond.Filter,
+		Limit:          cond.Limit,
+		Offset:         cond.Offset,
+		OutputFields:   cond.OutputFields,
+	}
+}
+
+func fromRpcSparse(items []*olama.SparseVecItem) [][]interface{} {
+	out := make([][]interface{}, 0, len(items))
+	for _, item := range items {
+		out = append(out, []interface{}{item.TermId, item.Score})
+	}
+	return out
+}
+
+// fromRpcDocument converts the fields to the json values of the http api
+func fromRpcDocument(d *olama.Document) *document.Document {
+	out := &document.Document{Id: d.Id, Vector: d.Vector, Fields: make(map[string]interface{})}
+	if len(d.SparseVector) != 0 {
+		out.SparseVector = fromRpcSparse(d.SparseVector)
+	}
+	for k, f := range d.Fields {
+		switch v := f.GetOneofVal().(type) {
+		case *olama.Field_ValStr:
+			out.Fields[k] = string(v.ValStr)
+		case *olama.Field_ValU64:
+			out.Fields[k] = json.Number(strconv.FormatUint(v.ValU64, 10))
+		case *olama.Field_ValDouble:
+			out.Fields[k] = json.Number(strconv.FormatFloat(v.ValDouble, 'g', -1, 64))
+		case *olama.Field_ValStrArr:
+			arr := make([]interface{}, 0, len(v.ValStrArr.GetStrArr()))
+			for _, s := range v.ValStrArr.GetStrArr() {
+				arr = append(arr, string(s))
+			}
+			out.Fields[k] = arr
+		}
+	}
+	return out
+}
+
+func toRpcDocument(d *document.Document) *olama.Document {
+	out := &olama.Document{Id: d.Id, Vector: d.Vector, Score: d.Score, Fields: make(map[string]*olama.Field)}
+	for _, pair := range d.SparseVector {
+		term, _ := filter.ToNumber(pair[0])
+		score, _ := filter.ToNumber(pair[1])
+		t, _ := term.Int64()
+		f, _ := score.Float32()
+		out.SparseVector = append(out.SparseVector, &olama.SparseVecItem{TermId: t, Score: f})
+	}
+	for k, v := range d.Fields {
+		switch val := v.(type) {
+		case string:
+			out.Fields[k] = &olama.Field{OneofVal: &olama.Field_ValStr{ValStr: []byte(val)}}
+		case json.Number:
+			if u, err := strconv.ParseUint(string(val), 10, 64); err == nil {
+				out.Fields[k] = &olama.Field{OneofVal: &olama.Field_ValU64{ValU64: u}}
+			} else if f, err := val.Float64(); err == nil {
+				out.Fields[k] = &olama.Field{OneofVal: &olama.Field_ValDouble{ValDouble: f}}
+			}
+		case []interface{}:
+			arr := make([][]byte, 0, len(val))
+			for _, elem := range val {
+				if s, ok := elem.(string); ok {
+					arr = append(arr, []byte(s))
+				} else {
+					arr = append(arr, []byte(jsonString(elem)))
+				}
+			}
+			out.Fields[k] = &olama.Field{OneofVal: &olama.Field_ValStrArr{ValStrArr: &olama.Field_StringArray{StrArr: arr}}}
+		}
+	}
+	return out
+}
+
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,224 @@
+package tcvectordbtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+)
+
+func TestRpcServer(t *testing.T) {
+	srv := NewRpcServer()
+	defer srv.Close()
+	cli, err := srv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	if v, err := cli.ServerVersion(ctx); err != nil || v.String() != "v1.4.0.0" {
+		t.Fatalf("unexpected server version %v, %v", v, err)
+	}
+	coll := createTestCollection(t, cli, tcvectordb.L2)
+
+	query, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{
+		Filter: tcvectordb.NewFilter(`author = "tom"`), RetrieveVector: true, Limit: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Total != 2 || len(query.Documents[0].Vector) != 2 || query.Documents[0].Fields["page"].Uint64() == 0 {
+		t.Fatalf("unexpected query result %+v", query.Documents)
+	}
+
+	search, err := coll.Search(ctx, [][]float32{{1, 0}}, &tcvectordb.SearchDocumentParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(search.Documents[0]) != 2 || search.Documents[0][0].Id != "0001" || search.Documents[0][1].Id != "0002" {
+		t.Fatalf("unexpected search result %+v", search.Documents)
+	}
+
+	limit := 1
+	hybrid, err := coll.HybridSearch(ctx, tcvectordb.HybridSearchDocumentParams{
+		Match: []*tcvectordb.MatchOption{{FieldName: "sparse_vector", Data: []encoder.SparseVecItem{{TermId: 2, Score: 1}}}},
+		Limit: &limit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hybrid.Documents[0]) != 1 || hybrid.Documents[0][0].Id != "0002" {
+		t.Fatalf("unexpected hybrid search result %+v", hybrid.Documents)
+	}
+
+	update, err := coll.Update(ctx, tcvectordb.UpdateDocumentParams{
+		QueryFilter:  tcvectordb.NewFilter(`page > 15`),
+		UpdateFields: map[string]tcvectordb.Field{"author": {Val: "spike"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.AffectedCount != 2 {
+		t.Fatalf("expect 2 documents updated, got %d", update.AffectedCount)
+	}
+	deleted, err := coll.Delete(ctx, tcvectordb.DeleteDocumentParams{Filter: tcvectordb.NewFilter(`author = "spike"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.AffectedCount != 2 {
+		t.Fatalf("expect 2 documents deleted, got %d", deleted.AffectedCount)
+	}
+
+	db := cli.Database("db")
+	if _, err = db.SetAlias(ctx, "coll", "coll_alias"); err != nil {
+		t.Fatal(err)
+	}
+	aliased, err := db.Collection("coll_alias").Query(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliased.Total != 1 {
+		t.Fatalf("expect 1 document by the alias, got %d", aliased.Total)
+	}
+	if _, err = db.DeleteAlias(ctx, "coll_alias"); err != nil {
+		t.Fatal(err)
+	}
+	var serverErr *tcvectordb.ServerError
+	if _, err = db.DeleteAlias(ctx, "coll_alias"); !errors.As(err, &serverErr) {
+		t.Fatalf("expect the server error of the deleted alias, got %v", err)
+	}
+	if _, err = db.Collection("coll_alias").Query(ctx, nil); !errors.Is(err, tcvectordb.ErrCollectionNotFound) {
+		t.Fatalf("expect ErrCollectionNotFound, got %v", err)
+	}
+	if _, err = cli.Database("none").DescribeCollection(ctx, "coll"); !errors.Is(err, tcvectordb.ErrDatabaseNotFound) {
+		t.Fatalf("expect ErrDatabaseNotFound, got %v", err)
+	}
+}
+
+func TestDropNotExist(t *testing.T) {
+	httpSrv := NewServer()
+	defer httpSrv.Close()
+	rpcSrv := NewRpcServer()
+	defer rpcSrv.Close()
+	httpCli, err := tcvectordb.NewClient(httpSrv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcCli, err := rpcSrv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcCli.Close()
+	ctx := context.Background()
+
+	for name, cli := range map[string]tcvectordb.DatabaseInterface{"http": httpCli, "grpc": rpcCli} {
+		if _, err = cli.DropDatabase(ctx, "missing"); err != nil {
+			t.Fatalf("%s: drop a not exist database should succeed, got %v", name, err)
+		}
+		if _, err = cli.CreateDatabase(ctx, "db"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = cli.Database("db").DropCollection(ctx, "missing"); err != nil {
+			t.Fatalf("%s: drop a not exist collection should succeed, got %v", name, err)
+		}
+	}
+}
+
+func TestRpcServerCollections(t *testing.T) {
+	srv := NewRpcServer()
+	defer srv.Close()
+	cli, err := srv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	createTestCollection(t, cli, tcvectordb.COSINE)
+
+	var serverErr *tcvectordb.ServerError
+	if _, err = cli.CreateDatabase(ctx, "db"); !errors.As(err, &serverErr) || serverErr.Code != CodeAlreadyExists {
+		t.Fatalf("expect the already exists error, got %v", err)
+	}
+	dbs, err := cli.ListDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dbs.Databases) != 1 || dbs.Databases[0].DatabaseName != "db" {
+		t.Fatalf("unexpected databases %+v", dbs.Databases)
+	}
+
+	db := cli.Database("db")
+	colls, err := db.ListCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colls.Collections) != 1 || colls.Collections[0].CollectionName != "coll" {
+		t.Fatalf("unexpected collections %+v", colls.Collections)
+	}
+	described, err := db.DescribeCollection(ctx, "coll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(described.Indexes.VectorIndex) != 1 || described.Indexes.VectorIndex[0].MetricType != tcvectordb.COSINE ||
+		len(described.Indexes.FilterIndex) != 5 || len(described.Indexes.SparseVectorIndex) != 1 ||
+		described.TtlConfig == nil || described.TtlConfig.TimeField != "expire_at" {
+		t.Fatalf("unexpected collection %+v", described.Collection)
+	}
+
+	rebuild, err := db.Collection("coll").RebuildIndex(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rebuild.TaskIds) != 1 {
+		t.Fatalf("unexpected rebuild tasks %v", rebuild.TaskIds)
+	}
+	if _, err = db.TruncateCollection(ctx, "coll"); err != nil {
+		t.Fatal(err)
+	}
+	query, err := db.Collection("coll").Query(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Total != 0 {
+		t.Fatalf("expect the truncated collection empty, got %d documents", query.Total)
+	}
+}
+
+func TestRpcServerSharedStore(t *testing.T) {
+	store := NewStore()
+	httpSrv := NewServerWithStore(store)
+	defer httpSrv.Close()
+	rpcSrv := NewRpcServerWithStore(store)
+	defer rpcSrv.Close()
+	rpcCli, err := rpcSrv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcCli.Close()
+	httpCli, err := tcvectordb.NewClient(httpSrv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// the documents written by grpc are read by http, and the other way around
+	createTestCollection(t, rpcCli, tcvectordb.L2)
+	if _, err = httpCli.Database("db").Collection("coll").Upsert(ctx, []tcvectordb.Document{
+		{Id: "0004", Vector: []float32{1, 1}, Fields: map[string]tcvectordb.Field{"author": {Val: "spike"}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for name, cli := range map[string]tcvectordb.DatabaseInterface{"http": httpCli, "grpc": rpcCli} {
+		query, err := cli.Database("db").Collection("coll").Query(ctx, nil, &tcvectordb.QueryDocumentParams{
+			Filter: tcvectordb.NewFilter(`author in ("tom", "spike")`), Limit: 10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query.Total != 3 {
+			t.Fatalf("%s: expect 3 documents, got %d", name, query.Total)
+		}
+	}
+}
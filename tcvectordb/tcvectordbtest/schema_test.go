@@ -0,0 +1,153 @@
+package tcvectordbtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/filter"
+)
+
+func TestSchemaValidation(t *testing.T) {
+	httpSrv := NewServer()
+	defer httpSrv.Close()
+	rpcSrv := NewRpcServer()
+	defer rpcSrv.Close()
+	option := tcvectordb.ClientOption{ValidateSchema: true}
+	httpCli, err := tcvectordb.NewClient(httpSrv.URL, "root", "key", &option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcCli, err := rpcSrv.NewClient("root", "key", &option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcCli.Close()
+	ctx := context.Background()
+
+	for name, cli := range map[string]tcvectordb.DatabaseInterface{"http": httpCli, "grpc": rpcCli} {
+		coll := createTestCollection(t, cli, tcvectordb.L2)
+
+		for cond, field := range map[string]string{
+			`title = "x"`:                    "title",
+			`author = "tom" and page = "10"`: "page",
+			`author include ("tom")`:         "author",
+			`tags = "a"`:                     "tags",
+			`tags include (1)`:               "tags",
+			`not (author = 1)`:               "author",
+			`vector = 1`:                     "vector",
+		} {
+			_, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(cond)})
+			var schemaErr *tcvectordb.SchemaError
+			if !errors.As(err, &schemaErr) || schemaErr.Field != field || !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+				t.Fatalf("%s %s: expect schema error of %s, got %v", name, cond, field, err)
+			}
+		}
+		var syntaxErr *filter.SyntaxError
+		if _, err = coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(`page >`)}); !errors.As(err, &syntaxErr) {
+			t.Fatalf("%s: expect syntax error, got %v", name, err)
+		}
+
+		badFilter := tcvectordb.NewFilter(`page = "10"`)
+		var schemaErr *tcvectordb.SchemaError
+		if _, err = coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{OutputFields: []string{"author", "title"}}); !errors.As(err, &schemaErr) || schemaErr.Field != "title" {
+			t.Fatalf("%s: expect the output field without index rejected, got %v", name, err)
+		}
+		if _, err = coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{OutputFields: []string{"author", "ti tle"}}); !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+			t.Fatalf("%s: expect the malformed output field rejected, got %v", name, err)
+		}
+		if _, err = coll.Search(ctx, [][]float32{{1, 0}}, &tcvectordb.SearchDocumentParams{Filter: badFilter}); !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+			t.Fatalf("%s: expect the search rejected, got %v", name, err)
+		}
+		if _, err = coll.HybridSearch(ctx, tcvectordb.HybridSearchDocumentParams{
+			AnnParams: []*tcvectordb.AnnParam{{Data: []float32{1, 0}}}, OutputFields: []string{""},
+		}); !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+			t.Fatalf("%s: expect the hybrid search rejected, got %v", name, err)
+		}
+		if _, err = coll.Delete(ctx, tcvectordb.DeleteDocumentParams{Filter: badFilter}); !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+			t.Fatalf("%s: expect the delete rejected, got %v", name, err)
+		}
+		if _, err = coll.Update(ctx, tcvectordb.UpdateDocumentParams{QueryFilter: badFilter,
+			UpdateFields: map[string]tcvectordb.Field{"title": {Val: "x"}}}); !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+			t.Fatalf("%s: expect the update rejected, got %v", name, err)
+		}
+
+		res, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{
+			Filter:       tcvectordb.NewFilter(`author in ("tom") and page > 10 and tags include all ("b") and id != "x"`),
+			OutputFields: []string{"id", "author", "vector", "sparse_vector", "score"},
+		})
+		if err != nil || res.Total != 1 {
+			t.Fatalf("%s: unexpected valid query result %+v, %v", name, res, err)
+		}
+
+		// the cached schema is dropped with the collection
+		db := cli.Database("db")
+		if _, err = db.DropCollection(ctx, "coll"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = db.CreateCollection(ctx, "coll", 1, 1, "", tcvectordb.Indexes{
+			VectorIndex: []tcvectordb.VectorIndex{{
+				FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+				Dimension:   2,
+				MetricType:  tcvectordb.L2,
+			}},
+			FilterIndex: []tcvectordb.FilterIndex{
+				{FieldName: "id", FieldType: tcvectordb.String, IndexType: tcvectordb.PRIMARY},
+				{FieldName: "title", FieldType: tcvectordb.String, IndexType: tcvectordb.FILTER},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(`title = "x"`)}); err != nil {
+			t.Fatalf("%s: the stale schema is used, %v", name, err)
+		}
+	}
+
+	// Validate works without the option
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.L2)
+	if err = coll.Validate(ctx, tcvectordb.NewFilter(`page include (1)`), nil); !errors.Is(err, tcvectordb.ErrSchemaMismatch) {
+		t.Fatalf("expect Validate to reject the filter, got %v", err)
+	}
+	if err = coll.Validate(ctx, tcvectordb.NewFilter(`tags exclude ("a")`), []string{"page"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSchemaAcceptedFilters the filters the server accepts must pass the validation
+func TestSchemaAcceptedFilters(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.L2)
+	ctx := context.Background()
+
+	for _, cond := range []string{
+		``,
+		`id = "0001"`,
+		`author = "tom"`,
+		`author != "tom"`,
+		`page > 10`,
+		`page >= 10 and page < 30`,
+		`page <= 20 or page = 21`,
+		`author in ("tom", "jerry")`,
+		`author not in ("tom")`,
+		`page in (10, 20)`,
+		`page not in (10)`,
+		`tags include ("a")`,
+		`tags exclude ("a", "b")`,
+		`tags include all ("a", "b")`,
+		`not (author = "tom")`,
+		`(author = "tom" or author = "jerry") and page > 10`,
+		`((author in ("tom") and (page > 10 or page < 5)) or not (tags include ("c")))`,
+		`author = "to\"m" and expire_at > 0`,
+	} {
+		if err := coll.Validate(ctx, tcvectordb.NewFilter(cond), nil); err != nil {
+			t.Fatalf("%s: %v", cond, err)
+		}
+		if _, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(cond)}); err != nil {
+			t.Fatalf("%s: the fake rejects the filter, %v", cond, err)
+		}
+	}
+}
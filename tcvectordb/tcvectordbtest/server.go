@@ -0,0 +1,125 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package tcvectordbtest provides an in-memory fake of the vectordb server for the offline tests.
+// It searches by brute force, so every vector index behaves like FLAT with the L2, IP or COSINE metric.
+//
+//	srv := tcvectordbtest.NewServer()
+//	defer srv.Close()
+//	cli, _ := tcvectordb.NewClient(srv.URL, "root", "key", nil)
+//
+// RpcServer serves the same store by grpc over an in-process bufconn listener:
+//
+//	srv := tcvectordbtest.NewRpcServer()
+//	defer srv.Close()
+//	cli, _ := srv.NewClient("root", "key", nil)
+//
+// Cassette records a session with the real server once and replays it without the server and the credentials.
+package tcvectordbtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api"
+)
+
+// Server is the fake vectordb http server. The endpoints are declared by the api.Meta tags of the requests:
+// /database/*, /collection/*, /alias/*, /index/rebuild and /document/*.
+type Server struct {
+	*httptest.Server
+	*Store
+
+	handlers map[string]func(body []byte) (interface{}, error)
+}
+
+// NewServer start a fake server with an empty store
+func NewServer() *Server {
+	return NewServerWithStore(NewStore())
+}
+
+// NewServerWithStore start a fake server on the store, which could be shared with the grpc fake server
+func NewServerWithStore(store *Store) *Server {
+	s := &Server{Store: store, handlers: make(map[string]func(body []byte) (interface{}, error))}
+	for _, fn := range []interface{}{
+		store.CreateDatabase, store.DropDatabase, store.ListDatabase,
+		store.CreateCollection, store.DescribeCollection, store.ListCollection,
+		store.DropCollection, store.TruncateCollection,
+		store.SetAlias, store.DeleteAlias, store.DescribeAlias, store.ListAlias,
+		store.RebuildIndex,
+		store.Upsert, store.Query, store.Search, store.HybridSearch, store.Delete, store.Update,
+	} {
+		s.register(fn)
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// register the store method like func(*database.CreateReq) (*database.CreateRes, error) on the path of the request
+func (s *Server) register(fn interface{}) {
+	fv := reflect.ValueOf(fn)
+	reqType := fv.Type().In(0).Elem()
+	path := api.Path(reflect.New(reqType).Interface())
+	s.handlers[path] = func(body []byte) (interface{}, error) {
+		req := reflect.New(reqType)
+		if len(strings.TrimSpace(string(body))) != 0 {
+			if err := json.Unmarshal(body, req.Interface()); err != nil {
+				return nil, errorf(CodeInvalidParameter, "invalid request body: %v", err)
+			}
+		}
+		out := fv.Call([]reflect.Value{req})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer account=") {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":1,"msg":"unauthorized"}`))
+		return
+	}
+	handler, ok := s.handlers[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":1,"msg":"not found"}`))
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	res, err := handler(body)
+	if err != nil {
+		e, ok := err.(*Error)
+		if !ok {
+			e = errorf(CodeInvalidParameter, "%v", err)
+		}
+		json.NewEncoder(w).Encode(api.CommonRes{Code: e.Code, Msg: e.Msg})
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,240 @@
+package tcvectordbtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvdbtext/encoder"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/filter"
+)
+
+func newTestCollection(t *testing.T, srv *Server, metric tcvectordb.MetricType) (*tcvectordb.Client, *tcvectordb.Collection) {
+	cli, err := tcvectordb.NewClient(srv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli, createTestCollection(t, cli, metric)
+}
+
+// createTestCollection creates db.coll with 3 documents, shared by the http and grpc tests
+func createTestCollection(t *testing.T, cli tcvectordb.DatabaseInterface, metric tcvectordb.MetricType) *tcvectordb.Collection {
+	ctx := context.Background()
+	db, err := cli.CreateDatabase(ctx, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll, err := db.CreateCollection(ctx, "coll", 1, 1, "", tcvectordb.Indexes{
+		VectorIndex: []tcvectordb.VectorIndex{{
+			FilterIndex: tcvectordb.FilterIndex{FieldName: "vector", FieldType: tcvectordb.Vector, IndexType: tcvectordb.FLAT},
+			Dimension:   2,
+			MetricType:  metric,
+		}},
+		SparseVectorIndex: []tcvectordb.SparseVectorIndex{{
+			FieldName: "sparse_vector", FieldType: tcvectordb.SparseVector,
+			IndexType: tcvectordb.SPARSE_INVERTED, MetricType: tcvectordb.IP,
+		}},
+		FilterIndex: []tcvectordb.FilterIndex{
+			{FieldName: "id", FieldType: tcvectordb.String, IndexType: tcvectordb.PRIMARY},
+			{FieldName: "author", FieldType: tcvectordb.String, IndexType: tcvectordb.FILTER},
+			{FieldName: "page", FieldType: tcvectordb.Uint64, IndexType: tcvectordb.FILTER},
+			{FieldName: "tags", FieldType: tcvectordb.Array, ElemType: tcvectordb.String, IndexType: tcvectordb.FILTER},
+			{FieldName: "expire_at", FieldType: tcvectordb.Uint64, IndexType: tcvectordb.FILTER},
+		},
+	}, &tcvectordb.CreateCollectionParams{
+		TtlConfig: &tcvectordb.TtlConfig{Enable: true, TimeField: "expire_at"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []tcvectordb.Document{
+		{Id: "0001", Vector: []float32{1, 0}, SparseVector: []encoder.SparseVecItem{{TermId: 1, Score: 0.9}},
+			Fields: map[string]tcvectordb.Field{"author": {Val: "jerry"}, "page": {Val: 10}, "tags": {Val: []string{"a", "b"}}}},
+		{Id: "0002", Vector: []float32{0.6, 0.8}, SparseVector: []encoder.SparseVecItem{{TermId: 2, Score: 0.5}},
+			Fields: map[string]tcvectordb.Field{"author": {Val: "tom"}, "page": {Val: 20}, "tags": {Val: []string{"b"}}}},
+		{Id: "0003", Vector: []float32{0, 1}, SparseVector: []encoder.SparseVecItem{{TermId: 1, Score: 0.1}},
+			Fields: map[string]tcvectordb.Field{"author": {Val: "tom"}, "page": {Val: 30}, "expire_at": {Val: 2000000000}}},
+	}
+	if _, err = coll.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	return coll
+}
+
+func TestServerQueryFilter(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cli, coll := newTestCollection(t, srv, tcvectordb.L2)
+	ctx := context.Background()
+
+	all, err := coll.Query(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cond, expect := range map[string]int{
+		`author = "tom"`:                          2,
+		`author = "tom" and page > 20`:            1,
+		`page >= 20 or author in ("jerry")`:       3,
+		`tags include ("a")`:                      1,
+		`tags include all ("a", "b")`:             1,
+		`tags exclude ("a")`:                      1,
+		`not (author = "tom")`:                    1,
+		`id not in ("0001", "0002")`:              1,
+		`page < 20 and (tags include ("b"))`:      1,
+		`author = "tom" and not (page != 30)`:     1,
+		`author="jerry" or (page<=10 and id="x")`: 1,
+	} {
+		res, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(cond)})
+		if err != nil {
+			t.Fatalf("%s: %v", cond, err)
+		}
+		if res.Total != uint64(expect) {
+			t.Fatalf("%s: expect %d documents, got %d", cond, expect, res.Total)
+		}
+		// the local evaluation agrees with the server
+		matched := 0
+		for _, doc := range all.Documents {
+			if ok, err := tcvectordb.NewFilter(cond).Match(doc); err != nil {
+				t.Fatalf("%s: %v", cond, err)
+			} else if ok {
+				matched++
+			}
+		}
+		if matched != expect {
+			t.Fatalf("%s: expect %d documents matched locally, got %d", cond, expect, matched)
+		}
+	}
+	if _, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: tcvectordb.NewFilter(`title = "x"`)}); err == nil {
+		t.Fatal("expect error for the field without filter index")
+	}
+
+	// the escaped value could not change the expression
+	f, err := tcvectordb.NewFilterExpr(filter.Or(filter.Eq("author", `x" or author = "tom`), filter.In("author", []string{"jerry"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: f}); err != nil || res.Total != 1 {
+		t.Fatalf("unexpected result of %s: %+v, %v", f.Cond(), res, err)
+	}
+
+	res, err := coll.Query(ctx, []string{"0002"}, &tcvectordb.QueryDocumentParams{RetrieveVector: true, OutputFields: []string{"page"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Documents) != 1 || len(res.Documents[0].Vector) != 2 || len(res.Documents[0].Fields) != 1 ||
+		res.Documents[0].Fields["page"].Uint64() != 20 {
+		t.Fatalf("unexpected documents %+v", res.Documents)
+	}
+
+	_, err = cli.Database("db").DescribeCollection(ctx, "missing")
+	if !errors.Is(err, tcvectordb.ErrCollectionNotFound) {
+		t.Fatalf("expect ErrCollectionNotFound, got %v", err)
+	}
+}
+
+// TestServerQueryEscapedValues the quotes and backslashes in the values reach the server escaped,
+// by the filter builder and by the legacy In, Include and the others on both transports
+func TestServerQueryEscapedValues(t *testing.T) {
+	httpSrv := NewServer()
+	defer httpSrv.Close()
+	rpcSrv := NewRpcServer()
+	defer rpcSrv.Close()
+	httpCli, err := tcvectordb.NewClient(httpSrv.URL, "root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcCli, err := rpcSrv.NewClient("root", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcCli.Close()
+	ctx := context.Background()
+
+	quoted, path := `say "hi"`, `C:\data\`
+	for name, cli := range map[string]tcvectordb.DatabaseInterface{"http": httpCli, "grpc": rpcCli} {
+		coll := createTestCollection(t, cli, tcvectordb.L2)
+		if _, err = coll.Upsert(ctx, []tcvectordb.Document{
+			{Id: "0004", Vector: []float32{1, 1}, Fields: map[string]tcvectordb.Field{
+				"author": {Val: quoted}, "tags": {Val: []string{path}}}},
+			{Id: "0005", Vector: []float32{1, 1}, Fields: map[string]tcvectordb.Field{
+				"author": {Val: path}, "tags": {Val: []string{quoted, "a"}}}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		eq, err := tcvectordb.NewFilterExpr(filter.Eq("author", quoted))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []struct {
+			filter *tcvectordb.Filter
+			expect string
+		}{
+			{eq, "0004"},
+			{tcvectordb.NewFilter(tcvectordb.In("author", []string{path})), "0005"},
+			{tcvectordb.NewFilter(tcvectordb.Include("tags", []string{path})), "0004"},
+			{tcvectordb.NewFilter(tcvectordb.IncludeAll("tags", []string{quoted, "a"})), "0005"},
+			{tcvectordb.NewFilter(tcvectordb.NotIn("author", []string{"tom", "jerry", quoted})), "0005"},
+		} {
+			res, err := coll.Query(ctx, nil, &tcvectordb.QueryDocumentParams{Filter: f.filter})
+			if err != nil {
+				t.Fatalf("%s %s: %v", name, f.filter.Cond(), err)
+			}
+			if res.Total != 1 || res.Documents[0].Id != f.expect {
+				t.Fatalf("%s %s: expect %s, got %+v", name, f.filter.Cond(), f.expect, res.Documents)
+			}
+		}
+	}
+}
+
+func TestServerSearch(t *testing.T) {
+	for metric, expect := range map[tcvectordb.MetricType][]string{
+		tcvectordb.L2:     {"0002", "0001", "0003"},
+		tcvectordb.IP:     {"0002", "0001", "0003"},
+		tcvectordb.COSINE: {"0002", "0001", "0003"},
+	} {
+		srv := NewServer()
+		_, coll := newTestCollection(t, srv, metric)
+		res, err := coll.Search(context.Background(), [][]float32{{0.8, 0.6}}, &tcvectordb.SearchDocumentParams{Limit: 3})
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, doc := range res.Documents[0] {
+			ids = append(ids, doc.Id)
+		}
+		if len(ids) != 3 || ids[0] != expect[0] || ids[1] != expect[1] || ids[2] != expect[2] {
+			t.Fatalf("%s: unexpected order %v", metric, ids)
+		}
+	}
+}
+
+func TestServerHybridSearchAndTTL(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, coll := newTestCollection(t, srv, tcvectordb.COSINE)
+	ctx := context.Background()
+
+	limit := 2
+	res, err := coll.HybridSearch(ctx, tcvectordb.HybridSearchDocumentParams{
+		Match: []*tcvectordb.MatchOption{{FieldName: "sparse_vector", Data: []encoder.SparseVecItem{{TermId: 1, Score: 1}}}},
+		Limit: &limit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Documents[0]) != 2 || res.Documents[0][0].Id != "0001" || res.Documents[0][1].Id != "0003" {
+		t.Fatalf("unexpected sparse match result %+v", res.Documents)
+	}
+
+	srv.SetNow(func() time.Time { return time.Unix(2000000001, 0) })
+	query, err := coll.Query(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Total != 2 {
+		t.Fatalf("the expired document is not removed, got %d documents", query.Total)
+	}
+}
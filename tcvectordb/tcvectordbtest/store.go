@@ -0,0 +1,393 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordbtest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/alias"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/collection"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/database"
+	"github.com/tencent/vectordatabase-sdk-go/tcvectordb/api/index"
+)
+
+// The response codes of the fake server. The database and collection not found codes are
+// tcvectordb.ERR_UNDEFINED_DATABASE and ERR_UNDEFINED_COLLECTION, the others, such as
+// the alias not found, are only distinguished by the message.
+const (
+	CodeInvalidParameter   = 1
+	CodeAlreadyExists      = 2
+	CodeDatabaseNotFound   = tcvectordb.ERR_UNDEFINED_DATABASE
+	CodeCollectionNotFound = tcvectordb.ERR_UNDEFINED_COLLECTION
+)
+
+// Error is a failure response with the code and msg
+type Error struct {
+	Code int32
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code: %d, message: %s", e.Code, e.Msg)
+}
+
+func errorf(code int32, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Msg: fmt.Sprintf(format, args...)}
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+type db struct {
+	name        string
+	dbType      string
+	createTime  time.Time
+	collections map[string]*coll
+	aliases     map[string]string
+}
+
+type coll struct {
+	item collection.DescribeCollectionItem
+	docs map[string]*doc
+	// order the document ids in the upsert order
+	order []string
+}
+
+// Store is the in-memory state of the fake vectordb, shared by the http and grpc front ends.
+// Its methods take the http api requests and return the http api responses.
+type Store struct {
+	mu        sync.Mutex
+	databases map[string]*db
+	now       func() time.Time
+	taskID    int
+
+	// KernalVersion is returned by GetVersion, v1.2.3.4 is 1002003004
+	KernalVersion int64
+}
+
+// NewStore new an empty store
+func NewStore() *Store {
+	return &Store{
+		databases:     make(map[string]*db),
+		now:           time.Now,
+		KernalVersion: 1004000000,
+	}
+}
+
+// SetNow set the clock used by the ttl expiration
+func (s *Store) SetNow(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+func (s *Store) database(name string) (*db, error) {
+	d, ok := s.databases[name]
+	if !ok {
+		return nil, errorf(CodeDatabaseNotFound, "database %s not exist", name)
+	}
+	return d, nil
+}
+
+// collection get the collection by name or alias
+func (s *Store) collection(database, name string) (*coll, error) {
+	d, err := s.database(database)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := d.collections[name]; ok {
+		return c, nil
+	}
+	if target, ok := d.aliases[name]; ok {
+		if c, ok := d.collections[target]; ok {
+			return c, nil
+		}
+	}
+	return nil, errorf(CodeCollectionNotFound, "collection %s.%s not exist", database, name)
+}
+
+// GetVersion returns the server time in milliseconds and KernalVersion
+func (s *Store) GetVersion() (timestamp, kernalVersion int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now().UnixNano() / int64(time.Millisecond), s.KernalVersion
+}
+
+func (s *Store) CreateDatabase(req *database.CreateReq) (*database.CreateRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Database == "" {
+		return nil, errorf(CodeInvalidParameter, "database name is empty")
+	}
+	if _, ok := s.databases[req.Database]; ok {
+		return nil, errorf(CodeAlreadyExists, "database %s already exist", req.Database)
+	}
+	s.databases[req.Database] = &db{
+		name:        req.Database,
+		dbType:      "BASE_DB",
+		createTime:  s.now(),
+		collections: make(map[string]*coll),
+		aliases:     make(map[string]string),
+	}
+	return &database.CreateRes{AffectedCount: 1}, nil
+}
+
+func (s *Store) DropDatabase(req *database.DropReq) (*database.DropRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.database(req.Database); err != nil {
+		return nil, err
+	}
+	delete(s.databases, req.Database)
+	return &database.DropRes{AffectedCount: 1}, nil
+}
+
+func (s *Store) ListDatabase(req *database.ListReq) (*database.ListRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := &database.ListRes{Info: make(map[string]database.DatabaseInfo)}
+	for name, d := range s.databases {
+		res.Databases = append(res.Databases, name)
+		res.Info[name] = database.DatabaseInfo{CreateTime: d.createTime.Format(timeLayout), DbType: d.dbType}
+	}
+	sort.Strings(res.Databases)
+	return res, nil
+}
+
+func (s *Store) CreateCollection(req *collection.CreateReq) (*collection.CreateRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	if req.Collection == "" {
+		return nil, errorf(CodeInvalidParameter, "collection name is empty")
+	}
+	if _, ok := d.collections[req.Collection]; ok {
+		return nil, errorf(CodeAlreadyExists, "collection %s.%s already exist", req.Database, req.Collection)
+	}
+	var hasPrimary, hasVector bool
+	for _, idx := range req.Indexes {
+		switch idx.FieldType {
+		case "vector":
+			hasVector = true
+			if idx.Dimension == 0 {
+				return nil, errorf(CodeInvalidParameter, "dimension of vector field %s is 0", idx.FieldName)
+			}
+			switch idx.MetricType {
+			case "L2", "IP", "COSINE":
+			default:
+				return nil, errorf(CodeInvalidParameter, "unsupported metric type %s", idx.MetricType)
+			}
+		case "string", "uint64", "array", "sparseVector":
+		default:
+			return nil, errorf(CodeInvalidParameter, "unsupported field type %s of %s", idx.FieldType, idx.FieldName)
+		}
+		if idx.IndexType == "primaryKey" {
+			hasPrimary = true
+		}
+	}
+	if !hasPrimary || !hasVector {
+		return nil, errorf(CodeInvalidParameter, "collection requires a primaryKey index and a vector index")
+	}
+	if req.TtlConfig != nil && req.TtlConfig.Enable {
+		idx := findIndex(req.Indexes, req.TtlConfig.TimeField)
+		if idx == nil || idx.FieldType != "uint64" || idx.IndexType != "filter" {
+			return nil, errorf(CodeInvalidParameter, "ttl time field %s must be an uint64 filter index", req.TtlConfig.TimeField)
+		}
+	}
+	c := &coll{docs: make(map[string]*doc)}
+	c.item = collection.DescribeCollectionItem{
+		Database:    req.Database,
+		Collection:  req.Collection,
+		ReplicaNum:  req.ReplicaNum,
+		ShardNum:    req.ShardNum,
+		CreateTime:  s.now().Format(timeLayout),
+		Description: req.Description,
+		Indexes:     req.Indexes,
+		IndexStatus: &collection.IndexStatus{Status: "ready"},
+		TtlConfig:   req.TtlConfig,
+	}
+	if req.Embedding.Field != "" {
+		c.item.Embedding = &collection.EmbeddingRes{Embedding: req.Embedding, Status: "enabled"}
+	}
+	d.collections[req.Collection] = c
+	return &collection.CreateRes{AffectedCount: 1}, nil
+}
+
+func findIndex(indexes []*api.IndexColumn, field string) *api.IndexColumn {
+	for _, idx := range indexes {
+		if idx.FieldName == field {
+			return idx
+		}
+	}
+	return nil
+}
+
+func (s *Store) describe(d *db, c *coll) *collection.DescribeCollectionItem {
+	s.expire(c)
+	item := c.item
+	item.DocumentCount = int64(len(c.docs))
+	item.Alias = []string{}
+	for a, target := range d.aliases {
+		if target == c.item.Collection {
+			item.Alias = append(item.Alias, a)
+		}
+	}
+	sort.Strings(item.Alias)
+	return &item
+}
+
+func (s *Store) DescribeCollection(req *collection.DescribeReq) (*collection.DescribeRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	return &collection.DescribeRes{Collection: s.describe(s.databases[req.Database], c)}, nil
+}
+
+func (s *Store) ListCollection(req *collection.ListReq) (*collection.ListRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	res := new(collection.ListRes)
+	for _, c := range d.collections {
+		res.Collections = append(res.Collections, s.describe(d, c))
+	}
+	sort.Slice(res.Collections, func(i, j int) bool {
+		return res.Collections[i].Collection < res.Collections[j].Collection
+	})
+	return res, nil
+}
+
+func (s *Store) DropCollection(req *collection.DropReq) (*collection.DropRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.collections[req.Collection]; !ok {
+		return nil, errorf(CodeCollectionNotFound, "collection %s.%s not exist", req.Database, req.Collection)
+	}
+	delete(d.collections, req.Collection)
+	if !req.WithoutAlias {
+		for a, target := range d.aliases {
+			if target == req.Collection {
+				delete(d.aliases, a)
+			}
+		}
+	}
+	return &collection.DropRes{AffectedCount: 1}, nil
+}
+
+func (s *Store) TruncateCollection(req *collection.TruncateReq) (*collection.TruncateRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(req.Database, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	if !req.OnlyFlushAnnIndex {
+		c.docs = make(map[string]*doc)
+		c.order = nil
+	}
+	return &collection.TruncateRes{AffectedCount: 1}, nil
+}
+
+func (s *Store) SetAlias(req *alias.SetReq) (*alias.SetRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.collections[req.Collection]; !ok {
+		return nil, errorf(CodeCollectionNotFound, "collection %s.%s not exist", req.Database, req.Collection)
+	}
+	if _, ok := d.collections[req.Alias]; ok || req.Alias == "" {
+		return nil, errorf(CodeInvalidParameter, "invalid alias %q", req.Alias)
+	}
+	d.aliases[req.Alias] = req.Collection
+	return &alias.SetRes{AffectedCount: 1}, nil
+}
+
+func (s *Store) DeleteAlias(req *alias.DeleteReq) (*alias.DeleteRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.aliases[req.Alias]; !ok {
+		return nil, errorf(CodeInvalidParameter, "alias %s not exist", req.Alias)
+	}
+	delete(d.aliases, req.Alias)
+	return &alias.DeleteRes{AffectedCount: 1}, nil
+}
+
+func (s *Store) DescribeAlias(req *alias.DescribeReq) (*alias.DescribeRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := d.aliases[req.Alias]
+	if !ok {
+		return nil, errorf(CodeInvalidParameter, "alias %s not exist", req.Alias)
+	}
+	return &alias.DescribeRes{Aliases: []*alias.AliasItem{{Alias: req.Alias, Collection: target}}}, nil
+}
+
+func (s *Store) ListAlias(req *alias.ListReq) (*alias.ListRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.database(req.Database)
+	if err != nil {
+		return nil, err
+	}
+	res := new(alias.ListRes)
+	for a, target := range d.aliases {
+		res.Aliases = append(res.Aliases, &alias.AliasItem{Alias: a, Collection: target})
+	}
+	sort.Slice(res.Aliases, func(i, j int) bool { return res.Aliases[i].Alias < res.Aliases[j].Alias })
+	return res, nil
+}
+
+// RebuildIndex the fake searches by brute force, so it only checks the collection and returns a task id
+func (s *Store) RebuildIndex(req *index.RebuildReq) (*index.RebuildRes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.collection(req.Database, req.Collection); err != nil {
+		return nil, err
+	}
+	s.taskID++
+	return &index.RebuildRes{TaskIds: []string{fmt.Sprintf("task-%d", s.taskID)}}, nil
+}
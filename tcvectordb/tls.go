@@ -0,0 +1,81 @@
+// Copyright (C) 2023 Tencent Cloud.
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the vectordb-sdk-java), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED,
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A
+// PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+// SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tcvectordb
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOption the tls settings used by both the http and grpc transports
+type TLSOption struct {
+	// CACertFile: the pem file of the CA bundle to verify the server, default use the system roots
+	CACertFile string
+	// CACertPEM: the pem content of the CA bundle, could be used together with CACertFile
+	CACertPEM []byte
+	// CertFile: the client certificate pem file for mTLS, must be set together with KeyFile
+	CertFile string
+	// KeyFile: the client private key pem file for mTLS
+	KeyFile string
+	// ServerName: override the server name used to verify the server certificate
+	ServerName string
+	// InsecureSkipVerify: skip the server certificate verification, only for testing.
+	// The http transport skipped it before TLSOption was added, set it to keep that behavior
+	InsecureSkipVerify bool
+}
+
+// tlsConfig build the tls.Config from the option, a nil option verifies the server with the system roots
+func (o *TLSOption) tlsConfig() (*tls.Config, error) {
+	if o == nil {
+		o = &TLSOption{}
+	}
+	config := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if o.CACertFile != "" || len(o.CACertPEM) != 0 {
+		pool := x509.NewCertPool()
+		if o.CACertFile != "" {
+			pem, err := os.ReadFile(o.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("read ca cert file failed, err: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in ca cert file %s", o.CACertFile)
+			}
+		}
+		if len(o.CACertPEM) != 0 && !pool.AppendCertsFromPEM(o.CACertPEM) {
+			return nil, fmt.Errorf("no certificate found in CACertPEM")
+		}
+		config.RootCAs = pool
+	}
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, fmt.Errorf("CertFile and KeyFile must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate failed, err: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
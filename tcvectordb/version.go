@@ -18,4 +18,48 @@
 
 package tcvectordb
 
+import "fmt"
+
 const SDKVersion = "v1.1.0"
+
+// ServerVersion the kernel version of the vectordb server, like v1.2.3.4
+type ServerVersion struct {
+	Major int
+	Minor int
+	Patch int
+	Build int
+}
+
+// ParseKernalVersion parse the kernal_version of GetVersion, v1.2.3.4 is returned as 1002003004
+func ParseKernalVersion(v int64) ServerVersion {
+	return ServerVersion{
+		Major: int(v / 1000000000),
+		Minor: int(v / 1000000 % 1000),
+		Patch: int(v / 1000 % 1000),
+		Build: int(v % 1000),
+	}
+}
+
+func (v ServerVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Build)
+}
+
+// IsZero reports whether the version is unknown
+func (v ServerVersion) IsZero() bool {
+	return v == ServerVersion{}
+}
+
+// Compare returns -1, 0 or 1 if v is older than, same as or newer than o
+func (v ServerVersion) Compare(o ServerVersion) int {
+	a := []int{v.Major, v.Minor, v.Patch, v.Build}
+	b := []int{o.Major, o.Minor, o.Patch, o.Build}
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}